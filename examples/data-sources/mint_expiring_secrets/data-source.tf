@@ -0,0 +1,8 @@
+data "mint_expiring_secrets" "production" {
+  vaults      = ["production"]
+  within_days = 14
+}
+
+output "secrets_to_rotate" {
+  value = [for secret in data.mint_expiring_secrets.production.secrets : "${secret.vault}/${secret.name} (${secret.expires_at})"]
+}
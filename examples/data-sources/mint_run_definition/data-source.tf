@@ -0,0 +1,48 @@
+resource "mint_secret" "access_token" {
+  vault        = "ci"
+  name         = "RWX_ACCESS_TOKEN"
+  secret_value = var.rwx_access_token
+}
+
+data "mint_run_definition" "ci" {
+  on {
+    github {
+      push {
+        init = {
+          commit-sha = "$${{ event.git.sha }}"
+        }
+      }
+    }
+  }
+
+  base {
+    os  = "ubuntu 24.04"
+    tag = "1.2"
+  }
+
+  task {
+    key  = "code"
+    call = "git/clone 1.8.0"
+    with = {
+      repository = "https://github.com/example/app.git"
+      ref        = "$${{ init.commit-sha }}"
+    }
+  }
+
+  task {
+    key = "test"
+    use = ["code"]
+    run = "make test"
+
+    env_secret {
+      name   = "RWX_ACCESS_TOKEN"
+      vault  = mint_secret.access_token.vault
+      secret = mint_secret.access_token.name
+    }
+  }
+}
+
+resource "local_file" "ci" {
+  filename = "${path.module}/.mint/ci.yml"
+  content  = data.mint_run_definition.ci.yaml
+}
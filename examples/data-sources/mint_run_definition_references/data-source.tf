@@ -0,0 +1,16 @@
+data "mint_run_definition_references" "ci" {
+  directory = "${path.module}/.mint"
+}
+
+# Fail the plan if a run definition uses a secret that isn't managed here.
+resource "terraform_data" "secrets_are_managed" {
+  lifecycle {
+    precondition {
+      condition = alltrue([
+        for secret in data.mint_run_definition_references.ci.secrets :
+        contains([for s in mint_secret.all : "${s.vault}/${s.name}"], "${secret.vault}/${secret.name}")
+      ])
+      error_message = "A run definition references a secret that is not managed by Terraform."
+    }
+  }
+}
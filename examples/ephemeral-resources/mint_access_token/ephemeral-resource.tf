@@ -0,0 +1,15 @@
+ephemeral "mint_access_token" "deploy" {
+  name   = "deploy-pipeline"
+  scopes = ["vaults:read"]
+  vaults = ["production"]
+  ttl    = "30m"
+}
+
+resource "terraform_data" "deploy" {
+  provisioner "local-exec" {
+    command = "./deploy.sh"
+    environment = {
+      RWX_ACCESS_TOKEN = ephemeral.mint_access_token.deploy.token
+    }
+  }
+}
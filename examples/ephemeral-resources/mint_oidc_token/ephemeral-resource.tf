@@ -0,0 +1,11 @@
+ephemeral "mint_oidc_token" "aws" {
+  vault = "default"
+  name  = "aws"
+}
+
+provider "aws" {
+  assume_role_with_web_identity {
+    role_arn           = "arn:aws:iam::123456789012:role/terraform"
+    web_identity_token = ephemeral.mint_oidc_token.aws.token
+  }
+}
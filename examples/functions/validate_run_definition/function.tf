@@ -0,0 +1,12 @@
+locals {
+  run_definition_errors = provider::mint::validate_run_definition(file("${path.module}/.mint/ci.yml"))
+}
+
+resource "terraform_data" "run_definition" {
+  lifecycle {
+    precondition {
+      condition     = length(local.run_definition_errors) == 0
+      error_message = join("\n\n", local.run_definition_errors[*].formatted)
+    }
+  }
+}
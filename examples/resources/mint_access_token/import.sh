@@ -0,0 +1,3 @@
+# Access tokens can be imported by their ID. The token value itself is only revealed when a token is
+# created, so it is null after import.
+terraform import mint_access_token.example 5b0c1a2e-8d4f-4b6a-9a3e-2f1d7c6b9e0a
@@ -0,0 +1,6 @@
+resource "mint_access_token" "captain" {
+  name          = "captain-uploads"
+  scopes        = ["captain:write"]
+  ttl           = "2160h"
+  rotate_before = "168h"
+}
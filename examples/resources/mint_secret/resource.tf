@@ -3,4 +3,75 @@ resource "mint_secret" "example" {
   name         = "my-secret"
   secret_value = "a-secret-token"
   description  = "holds a secret token"
+  expires_at   = "2030-01-01T00:00:00Z"
+}
+
+resource "mint_secret" "webhook_signing_key" {
+  vault = "default"
+  name  = "WEBHOOK_SIGNING_KEY"
+
+  generate {
+    length   = 32
+    encoding = "hex"
+  }
+
+  keepers = {
+    rotated_at = time_rotating.webhook_signing_key.id
+  }
+}
+
+resource "mint_secret" "android_keystore" {
+  vault               = "default"
+  name                = "ANDROID_KEYSTORE"
+  secret_value_base64 = filebase64("${path.module}/release.jks")
+}
+
+resource "mint_secret" "registry_password" {
+  vault = "default"
+  name  = "REGISTRY_PASSWORD"
+
+  secret_value_sops = {
+    file = "${path.module}/secrets.enc.yaml"
+    key  = "registry.password"
+  }
+}
+
+resource "mint_secret" "tls_key" {
+  vault        = "default"
+  name         = "TLS_KEY"
+  secret_value = file("${path.module}/tls.key")
+  format       = "pem"
+}
+
+resource "mint_secret" "deploy_token" {
+  vault            = "default"
+  name             = "DEPLOY_TOKEN"
+  secret_value_age = file("${path.module}/deploy_token.age")
+}
+
+# Write the same credential to several vaults from one resource.
+resource "mint_secret" "registry_credential" {
+  vaults       = ["default", "deploys", "previews"]
+  name         = "REGISTRY_CREDENTIAL"
+  secret_value = "a-registry-credential"
+}
+
+# Promote a variable to a secret. The secret is written before the variable is deleted, so it must
+# keep the variable's vault and name, and no secret of that name may exist yet.
+moved {
+  from = mint_variable.database_url
+  to   = mint_secret.database_url
+}
+
+resource "mint_secret" "database_url" {
+  vault        = "default"
+  name         = "DATABASE_URL"
+  secret_value = "postgres://app@db.internal/app"
+}
+
+# Describe a secret that was created outside of Terraform without knowing its value.
+resource "mint_secret" "legacy_token" {
+  vault       = "default"
+  name        = "LEGACY_TOKEN"
+  description = "rotated by the platform team"
 }
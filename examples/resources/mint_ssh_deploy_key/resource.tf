@@ -0,0 +1,19 @@
+resource "time_rotating" "deploy_key" {
+  rotation_days = 90
+}
+
+resource "mint_ssh_deploy_key" "example" {
+  vault = "default"
+  name  = "GITHUB_DEPLOY_KEY"
+
+  keepers = {
+    rotated_at = time_rotating.deploy_key.id
+  }
+}
+
+resource "github_repository_deploy_key" "example" {
+  repository = "example"
+  title      = "Mint (${mint_ssh_deploy_key.example.fingerprint_sha256})"
+  key        = mint_ssh_deploy_key.example.public_key
+  read_only  = true
+}
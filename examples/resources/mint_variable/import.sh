@@ -1,3 +1,4 @@
 # Variables can be imported by specifying the vault & variable name
+# The imported value is kept in sensitive_value until an apply moves it to the attribute in your configuration
 terraform import mint_variable.example default/my-var
 
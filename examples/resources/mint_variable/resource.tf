@@ -1,5 +1,22 @@
 resource "mint_variable" "example" {
-  vault        = "default"
-  name         = "my-variable"
-  secret_value = "foobar"
+  vault = "default"
+  name  = "my-variable"
+  value = "foobar"
+}
+
+# Structured config is compared by content, so reordered keys don't show as a change.
+resource "mint_variable" "deploy_config" {
+  vault = "default"
+  name  = "DEPLOY_CONFIG"
+  value_json = jsonencode({
+    regions  = ["us-east-1", "eu-west-1"]
+    replicas = 3
+  })
+}
+
+# Values that aren't secrets but must stay out of CI logs are hidden from plans, logs and diagnostics.
+resource "mint_variable" "account_id" {
+  vault           = "default"
+  name            = "ACCOUNT_ID"
+  sensitive_value = var.account_id
 }
@@ -0,0 +1,12 @@
+package api
+
+import "time"
+
+type AccessToken struct {
+	ID        string    `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	Vaults    []string  `json:"vault_names,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Token     string    `json:"token,omitempty"`
+}
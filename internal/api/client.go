@@ -2,15 +2,30 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client is an API Client for Mint
 type Client struct {
 	RoundTrip func(*http.Request) (*http.Response, error)
+
+	// ReadOnly makes every mutating call fail with ErrReadOnly before a request is sent.
+	ReadOnly bool
 }
 
 func NewClient(cfg Config) (Client, error) {
@@ -32,18 +47,207 @@ func NewClient(cfg Config) (Client, error) {
 		return http.DefaultClient.Do(req)
 	}
 
-	return Client{roundTrip}, nil
+	return Client{RoundTrip: roundTrip, ReadOnly: cfg.ReadOnly}, nil
+}
+
+const (
+	maxAttempts  = 3
+	retryBackoff = 500 * time.Millisecond
+)
+
+// do sends a request through RoundTrip, logging every attempt. Reads and requests carrying an
+// idempotency key are retried when they fail with a network error or a transient server response.
+// A mutating request without a key from WithIdempotencyKey gets a new one, kept across its retries.
+// Any sensitive values are masked in the logs in addition to credentials and secret payloads.
+func (c Client) do(req *http.Request, sensitiveValues ...string) (*http.Response, error) {
+	ctx := withRequestLogging(req.Context(), sensitiveValues...)
+
+	// Every attempt at a mutating request carries the same Idempotency-Key, so retrying a write
+	// that Mint already applied replays the original response instead of applying it twice.
+	if isMutatingMethod(req.Method) && req.Header.Get(idempotencyKeyHeader) == "" {
+		req.Header.Set(idempotencyKeyHeader, idempotencyKeyFromContext(ctx))
+	}
+	retryable := req.Method == http.MethodGet || req.Header.Get(idempotencyKeyHeader) != ""
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLPath(req.URL.Path),
+	)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("unable to rewind request body: %w", err)
+			}
+			req.Body = body
+		}
+
+		logRequest(ctx, req, attempt)
+
+		start := time.Now()
+		resp, err := c.RoundTrip(req)
+		latency := time.Since(start)
+
+		if err != nil {
+			logRequestError(ctx, req, attempt, latency, err)
+
+			if !retryable || attempt >= maxAttempts || ctx.Err() != nil {
+				span.SetAttributes(tracing.RetriesKey.Int(attempt - 1))
+				tracing.RecordError(span, err)
+				return nil, err
+			}
+		} else {
+			logResponse(ctx, req, resp, attempt, latency)
+
+			if !retryable || attempt >= maxAttempts || !isTransientStatus(resp.StatusCode) {
+				span.SetAttributes(
+					semconv.HTTPResponseStatusCode(resp.StatusCode),
+					tracing.RetriesKey.Int(attempt-1),
+				)
+				if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+					span.SetStatus(codes.Error, resp.Status)
+				}
+				return resp, nil
+			}
+
+			resp.Body.Close() //nolint:errcheck
+		}
+
+		select {
+		case <-ctx.Done():
+			tracing.RecordError(span, ctx.Err())
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempt) * retryBackoff):
+		}
+	}
+}
+
+func isTransientStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c Client) CreateAccessToken(ctx context.Context, token AccessToken) (AccessToken, error) {
+	ctx, span := tracing.Start(ctx, "api.CreateAccessToken")
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return AccessToken{}, ErrReadOnly
+	}
+
+	endpoint := "/mint/api/access_tokens"
+
+	encodedBody, err := json.Marshal(token)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("unable to encode as JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(encodedBody))
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("unable to create new HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
+		msg := extractErrorMessage(resp.Body)
+		if msg == "" {
+			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
+		}
+
+		return AccessToken{}, errors.New(msg)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return AccessToken{}, fmt.Errorf("unable to decode JSON response: %w", err)
+	}
+
+	return token, nil
+}
+
+func (c Client) CreateOIDCTokenInVault(ctx context.Context, vault string, token OIDCToken) (OIDCToken, error) {
+	ctx, span := tracing.Start(ctx, "api.CreateOIDCTokenInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
+	endpoint := "/mint/api/vaults/oidc_tokens"
+
+	requestBody := struct {
+		Name      string `json:"name"`
+		VaultName string `json:"vault_name"`
+	}{
+		Name:      token.Name,
+		VaultName: vault,
+	}
+
+	encodedBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return OIDCToken{}, fmt.Errorf("unable to encode as JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(encodedBody))
+	if err != nil {
+		return OIDCToken{}, fmt.Errorf("unable to create new HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return OIDCToken{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != 200 {
+		if resp.StatusCode == 404 {
+			return OIDCToken{}, ErrNotFound
+		}
+
+		msg := extractErrorMessage(resp.Body)
+		if msg == "" {
+			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
+		}
+
+		return OIDCToken{}, errors.New(msg)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return OIDCToken{}, fmt.Errorf("unable to decode JSON response: %w", err)
+	}
+
+	return token, nil
 }
 
-func (c Client) DeleteSecretInVault(vault string, secret Secret) error {
+func (c Client) DeleteSecretInVault(ctx context.Context, vault string, secret Secret) error {
+	ctx, span := tracing.Start(ctx, "api.DeleteSecretInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return ErrReadOnly
+	}
+
 	endpoint := "/mint/api/vaults/secrets"
 
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, secret.Name, vault), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, secret.Name, vault), nil)
 	if err != nil {
 		return fmt.Errorf("unable to create new HTTP request: %w", err)
 	}
 
-	resp, err := c.RoundTrip(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -61,15 +265,23 @@ func (c Client) DeleteSecretInVault(vault string, secret Secret) error {
 	return nil
 }
 
-func (c Client) DeleteVariableInVault(vault string, variable Variable) error {
+func (c Client) DeleteVariableInVault(ctx context.Context, vault string, variable Variable) error {
+	ctx, span := tracing.Start(ctx, "api.DeleteVariableInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return ErrReadOnly
+	}
+
 	endpoint := "/mint/api/vaults/vars"
 
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, variable.Name, vault), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, variable.Name, vault), nil)
 	if err != nil {
 		return fmt.Errorf("unable to create new HTTP request: %w", err)
 	}
 
-	resp, err := c.RoundTrip(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -87,15 +299,55 @@ func (c Client) DeleteVariableInVault(vault string, variable Variable) error {
 	return nil
 }
 
-func (c Client) GetSecretMetadataInVault(vault string, secret Secret) (Secret, error) {
+func (c Client) GetAccessToken(ctx context.Context, token AccessToken) (AccessToken, error) {
+	ctx, span := tracing.Start(ctx, "api.GetAccessToken")
+	defer span.End()
+
+	endpoint := "/mint/api/access_tokens"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(token.ID)), nil)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("unable to create new HTTP request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != 200 {
+		if resp.StatusCode == 404 {
+			return AccessToken{}, ErrNotFound
+		}
+
+		msg := extractErrorMessage(resp.Body)
+		if msg == "" {
+			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
+		}
+
+		return AccessToken{}, errors.New(msg)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return AccessToken{}, fmt.Errorf("unable to decode JSON response: %w", err)
+	}
+
+	return token, nil
+}
+
+func (c Client) GetSecretMetadataInVault(ctx context.Context, vault string, secret Secret) (Secret, error) {
+	ctx, span := tracing.Start(ctx, "api.GetSecretMetadataInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
 	endpoint := "/mint/api/vaults/secrets"
 
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, secret.Name, vault), nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, secret.Name, vault), nil)
 	if err != nil {
 		return Secret{}, fmt.Errorf("unable to create new HTTP request: %w", err)
 	}
 
-	resp, err := c.RoundTrip(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return Secret{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -121,15 +373,22 @@ func (c Client) GetSecretMetadataInVault(vault string, secret Secret) (Secret, e
 	return secret, nil
 }
 
-func (c Client) GetVariableInVault(vault string, variable Variable) (Variable, error) {
+func (c Client) GetVariableInVault(ctx context.Context, vault string, variable Variable) (Variable, error) {
+	ctx, span := tracing.Start(ctx, "api.GetVariableInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
 	endpoint := "/mint/api/vaults/vars"
 
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, variable.Name, vault), nil)
+	if variable.Sensitive {
+		ctx = withSensitiveValueField(ctx)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s?vault_name=%s", endpoint, variable.Name, vault), nil)
 	if err != nil {
 		return Variable{}, fmt.Errorf("unable to create new HTTP request: %w", err)
 	}
 
-	resp, err := c.RoundTrip(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return Variable{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -148,14 +407,110 @@ func (c Client) GetVariableInVault(vault string, variable Variable) (Variable, e
 		return Variable{}, errors.New(msg)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&variable); err != nil {
+	// Empty values are valid, so the value is decoded separately to tell them from a missing one.
+	response := struct {
+		*Variable
+		Value *string `json:"value"`
+	}{Variable: &variable}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return Variable{}, fmt.Errorf("unable to decode JSON response: %w", err)
 	}
 
+	variable.Value, variable.ValueOmitted = "", response.Value == nil
+	if response.Value != nil {
+		variable.Value = *response.Value
+	}
+
 	return variable, nil
 }
 
-func (c Client) SetSecretInVault(vault string, secret Secret) (Secret, error) {
+// ListExpiringSecrets returns the metadata of every secret that expires before the given time,
+// including those that have already expired. When vaults is empty, every vault the access token can
+// read is included.
+func (c Client) ListExpiringSecrets(ctx context.Context, before time.Time, vaults []string) ([]VaultSecret, error) {
+	ctx, span := tracing.Start(ctx, "api.ListExpiringSecrets")
+	defer span.End()
+
+	endpoint := "/mint/api/vaults/secrets/expiring"
+
+	query := url.Values{"expires_before": {before.UTC().Format(time.RFC3339)}}
+	for _, vault := range vaults {
+		query.Add("vault_name", vault)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s?%s", endpoint, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new HTTP request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != 200 {
+		msg := extractErrorMessage(resp.Body)
+		if msg == "" {
+			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
+		}
+
+		return nil, errors.New(msg)
+	}
+
+	var response = struct {
+		Secrets []VaultSecret `json:"secrets"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON response: %w", err)
+	}
+
+	return response.Secrets, nil
+}
+
+func (c Client) RevokeAccessToken(ctx context.Context, token AccessToken) error {
+	ctx, span := tracing.Start(ctx, "api.RevokeAccessToken")
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return ErrReadOnly
+	}
+
+	endpoint := "/mint/api/access_tokens"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(token.ID)), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create new HTTP request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != 200 && resp.StatusCode != 204 && resp.StatusCode != 404 {
+		msg := extractErrorMessage(resp.Body)
+		if msg == "" {
+			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
+		}
+
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func (c Client) SetSecretInVault(ctx context.Context, vault string, secret Secret) (Secret, error) {
+	ctx, span := tracing.Start(ctx, "api.SetSecretInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return Secret{}, ErrReadOnly
+	}
+
 	endpoint := "/mint/api/vaults/secrets"
 
 	requestBody := struct {
@@ -171,14 +526,14 @@ func (c Client) SetSecretInVault(vault string, secret Secret) (Secret, error) {
 		return Secret{}, fmt.Errorf("unable to encode as JSON: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(encodedBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(encodedBody))
 	if err != nil {
 		return Secret{}, fmt.Errorf("unable to create new HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.RoundTrip(req)
+	resp, err := c.do(req, secret.SecretValue)
 	if err != nil {
 		return Secret{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -194,6 +549,10 @@ func (c Client) SetSecretInVault(vault string, secret Secret) (Secret, error) {
 			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
 		}
 
+		if resp.StatusCode == 409 || resp.StatusCode == 412 {
+			return Secret{}, fmt.Errorf("%w: %s", ErrConflict, msg)
+		}
+
 		return Secret{}, errors.New(msg)
 	}
 
@@ -212,9 +571,23 @@ func (c Client) SetSecretInVault(vault string, secret Secret) (Secret, error) {
 	return secret, nil
 }
 
-func (c Client) SetVariableInVault(vault string, variable Variable) (Variable, error) {
+func (c Client) SetVariableInVault(ctx context.Context, vault string, variable Variable) (Variable, error) {
+	ctx, span := tracing.Start(ctx, "api.SetVariableInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return Variable{}, ErrReadOnly
+	}
+
 	endpoint := "/mint/api/vaults/vars"
 
+	var sensitiveValues []string
+	if variable.Sensitive {
+		sensitiveValues = append(sensitiveValues, variable.Value)
+		ctx = withSensitiveValueField(ctx)
+	}
+
 	requestBody := struct {
 		Var       Variable `json:"var"`
 		VaultName string   `json:"vault_name"`
@@ -228,14 +601,14 @@ func (c Client) SetVariableInVault(vault string, variable Variable) (Variable, e
 		return Variable{}, fmt.Errorf("unable to encode as JSON: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer(encodedBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(encodedBody))
 	if err != nil {
 		return Variable{}, fmt.Errorf("unable to create new HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.RoundTrip(req)
+	resp, err := c.do(req, sensitiveValues...)
 	if err != nil {
 		return Variable{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -251,8 +624,83 @@ func (c Client) SetVariableInVault(vault string, variable Variable) (Variable, e
 			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
 		}
 
+		if resp.StatusCode == 409 || resp.StatusCode == 412 {
+			return Variable{}, fmt.Errorf("%w: %s", ErrConflict, msg)
+		}
+
 		return Variable{}, errors.New(msg)
 	}
 
+	var response = struct {
+		Version int `json:"version"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil && !errors.Is(err, io.EOF) {
+		return Variable{}, fmt.Errorf("unable to decode JSON response: %w", err)
+	}
+
+	variable.Version = response.Version
+
 	return variable, nil
 }
+
+// UpdateSecretMetadataInVault changes a secret's description and expiry without sending or
+// changing its value, so the secret keeps its version. Only the given fields are sent; Mint leaves
+// the others as they are.
+func (c Client) UpdateSecretMetadataInVault(ctx context.Context, vault string, secret Secret, fields ...SecretMetadataField) (Secret, error) {
+	ctx, span := tracing.Start(ctx, "api.UpdateSecretMetadataInVault", tracing.VaultKey.String(vault))
+	defer span.End()
+
+	if c.ReadOnly {
+		tracing.RecordError(span, ErrReadOnly)
+		return Secret{}, ErrReadOnly
+	}
+
+	endpoint := "/mint/api/vaults/secrets"
+
+	requestBody := map[string]any{"vault_name": vault}
+	for _, field := range fields {
+		switch field {
+		case SecretDescriptionField:
+			requestBody[string(field)] = secret.Description
+		case SecretExpiresAtField:
+			requestBody[string(field)] = secret.ExpiresAt
+		}
+	}
+
+	encodedBody, err := json.Marshal(requestBody)
+	if err != nil {
+		return Secret{}, fmt.Errorf("unable to encode as JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, fmt.Sprintf("%s/%s", endpoint, url.PathEscape(secret.Name)), bytes.NewBuffer(encodedBody))
+	if err != nil {
+		return Secret{}, fmt.Errorf("unable to create new HTTP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return Secret{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != 200 {
+		if resp.StatusCode == 404 {
+			return Secret{}, ErrNotFound
+		}
+
+		msg := extractErrorMessage(resp.Body)
+		if msg == "" {
+			msg = fmt.Sprintf("Unable to call Mint API - %s", resp.Status)
+		}
+
+		return Secret{}, errors.New(msg)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return Secret{}, fmt.Errorf("unable to decode JSON response: %w", err)
+	}
+
+	return secret, nil
+}
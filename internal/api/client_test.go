@@ -0,0 +1,262 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a Client that sends every request to handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{AccessToken: "super-secret-token", Host: strings.TrimPrefix(server.URL, "http://"), Version: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roundTrip := client.RoundTrip
+	client.RoundTrip = func(req *http.Request) (*http.Response, error) {
+		req.URL.Scheme = "http"
+		return roundTrip(req)
+	}
+
+	return client
+}
+
+func TestSetSecretInVaultSendsExpectedVersion(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Secrets []Secret `json:"secrets"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		if body.Secrets[0].ExpectedVersion != 3 {
+			t.Errorf("expected expected_version 3, got %d", body.Secrets[0].ExpectedVersion)
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":"secret API_KEY is at version 4"}`))
+	})
+
+	_, err := client.SetSecretInVault(context.Background(), "default", Secret{Name: "API_KEY", SecretValue: "hunter2", ExpectedVersion: 3})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestSetVariableInVaultConflict(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+	})
+
+	_, err := client.SetVariableInVault(context.Background(), "default", Variable{Name: "HOST", Value: "example.com", ExpectedVersion: 1})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestGetRetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"name":"API_KEY","version":2}`))
+	})
+
+	secret, err := client.GetSecretMetadataInVault(context.Background(), "default", Secret{Name: "API_KEY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 2 || secret.Version != 2 {
+		t.Errorf("expected the read to succeed on its second attempt, got version %d after %d attempts", secret.Version, attempts)
+	}
+}
+
+func TestRetriesGiveUpAfterMaxAttempts(t *testing.T) {
+	attempts := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, err := client.GetSecretMetadataInVault(context.Background(), "default", Secret{Name: "API_KEY"}); err == nil {
+		t.Fatal("expected an error once every attempt failed")
+	}
+	if attempts != maxAttempts {
+		t.Errorf("expected %d attempts, got %d", maxAttempts, attempts)
+	}
+}
+
+func TestPermanentFailuresAreNotRetried(t *testing.T) {
+	attempts := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	if _, err := client.SetSecretInVault(context.Background(), "default", Secret{Name: "API_KEY", SecretValue: "hunter2"}); err == nil {
+		t.Fatal("expected an error for a rejected write")
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestSetSecretInVaultRetriesWithSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Idempotent-Replayed", "true")
+		_, _ = w.Write([]byte(`{"versions":{"API_KEY":1}}`))
+	})
+
+	ctx := WithIdempotencyKey(context.Background(), "create-abc")
+	secret, err := client.SetSecretInVault(ctx, "default", Secret{Name: "API_KEY", SecretValue: "hunter2", CreateOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Version != 1 {
+		t.Errorf("expected version 1, got %d", secret.Version)
+	}
+	if len(keys) != 2 || keys[0] != "create-abc" || keys[1] != "create-abc" {
+		t.Errorf("expected two attempts with idempotency key create-abc, got %v", keys)
+	}
+}
+
+func TestSetSecretInVaultGeneratesKeyPerCall(t *testing.T) {
+	var keys []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"versions":{"API_KEY":1}}`))
+	})
+
+	// The same create twice, as after deleting and recreating a secret, must not be replayed.
+	for range 2 {
+		if _, err := client.SetSecretInVault(context.Background(), "default", Secret{Name: "API_KEY", SecretValue: "hunter2", CreateOnly: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(keys) != 3 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("expected the retry to reuse the first call's key, got %v", keys)
+	}
+	if keys[2] == keys[1] {
+		t.Errorf("expected the second call to send a new key, got %v", keys)
+	}
+}
+
+func TestUpdateSecretMetadataInVaultOmitsValue(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/mint/api/vaults/secrets/API_KEY" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := body["secret"]; ok {
+			t.Error("expected the secret value to be omitted")
+		}
+		if _, ok := body["expires_at"]; ok {
+			t.Error("expected the expiry, which wasn't asked for, to be omitted")
+		}
+		if body["description"] != "rotated quarterly" || body["vault_name"] != "default" {
+			t.Errorf("unexpected body %v", body)
+		}
+
+		_, _ = w.Write([]byte(`{"name":"API_KEY","description":"rotated quarterly","version":4}`))
+	})
+
+	secret, err := client.UpdateSecretMetadataInVault(context.Background(), "default", Secret{Name: "API_KEY", Description: "rotated quarterly"}, SecretDescriptionField)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Version != 4 {
+		t.Errorf("expected version 4, got %d", secret.Version)
+	}
+}
+
+func TestListExpiringSecrets(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("expires_before") != "2030-01-31T00:00:00Z" {
+			t.Errorf("unexpected expires_before %q", query.Get("expires_before"))
+		}
+		if vaults := query["vault_name"]; len(vaults) != 2 || vaults[0] != "default" || vaults[1] != "production" {
+			t.Errorf("unexpected vault_name %v", vaults)
+		}
+
+		_, _ = w.Write([]byte(`{"secrets":[{"vault_name":"production","name":"API_KEY","description":"","expires_at":"2030-01-15T00:00:00Z","version":2}]}`))
+	})
+
+	secrets, err := client.ListExpiringSecrets(context.Background(), time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC), []string{"default", "production"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 1 || secrets[0].VaultName != "production" || secrets[0].Name != "API_KEY" || secrets[0].ExpiresAt == nil || secrets[0].ExpiresAt.Day() != 15 {
+		t.Errorf("unexpected secrets %+v", secrets)
+	}
+}
+
+func TestGetVariableInVaultTimestamps(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"HOSTNAME","value":"internal.example.com","version":3,"created_at":"2030-01-01T00:00:00Z","updated_at":"2030-01-02T00:00:00Z","updated_by":"dan@example.com"}`))
+	})
+
+	variable, err := client.GetVariableInVault(context.Background(), "default", Variable{Name: "HOSTNAME"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if variable.CreatedAt == nil || variable.CreatedAt.Day() != 1 || variable.UpdatedAt == nil || variable.UpdatedAt.Day() != 2 || variable.UpdatedBy != "dan@example.com" {
+		t.Errorf("unexpected variable %+v", variable)
+	}
+}
+
+func TestGetVariableInVaultEmptyValue(t *testing.T) {
+	for body, omitted := range map[string]bool{
+		`{"name":"EXTRA_ARGS","value":"","version":1}`: false,
+		`{"name":"EXTRA_ARGS","version":1}`:            true,
+	} {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		})
+
+		variable, err := client.GetVariableInVault(context.Background(), "default", Variable{Name: "EXTRA_ARGS", Value: "stale"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if variable.Value != "" || variable.ValueOmitted != omitted || variable.Version != 1 {
+			t.Errorf("%s: unexpected variable %+v", body, variable)
+		}
+	}
+}
@@ -21,6 +21,11 @@ type StackEntry struct {
 	Name     string `json:"name"`
 }
 
+// String renders the error message the same way errors from the Mint API are presented to users.
+func (e ErrorMessage) String() string {
+	return formatUserMessage(e.Message, e.Frame, e.StackTrace, e.Advice)
+}
+
 // extractErrorMessage is a small helper function for parsing an API error message
 func extractErrorMessage(reader io.Reader) string {
 	errorStruct := struct {
@@ -36,7 +41,7 @@ func extractErrorMessage(reader io.Reader) string {
 		var message strings.Builder
 		for _, errorMessage := range errorStruct.ErrorMessages {
 			message.WriteString("\n\n")
-			message.WriteString(formatUserMessage(errorMessage.Message, errorMessage.Frame, errorMessage.StackTrace, errorMessage.Advice))
+			message.WriteString(errorMessage.String())
 		}
 
 		return message.String()
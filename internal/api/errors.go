@@ -2,4 +2,8 @@ package api
 
 import "errors"
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("modified since it was last read")
+	ErrReadOnly = errors.New("the Mint provider is configured as read-only and will not modify any resources")
+)
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyKeyRandomBytes = 16
+)
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context whose mutating requests are sent with the given
+// Idempotency-Key, allowing Mint to recognise a retry of a write it has already applied and
+// replay the original response rather than applying the write again.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// NewIdempotencyKey returns a random idempotency key.
+func NewIdempotencyKey() string {
+	b := make([]byte, idempotencyKeyRandomBytes)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// idempotencyKeyFromContext returns the key set with WithIdempotencyKey, or a new random key.
+func idempotencyKeyFromContext(ctx context.Context) string {
+	if key, ok := ctx.Value(idempotencyKeyContextKey{}).(string); ok && key != "" {
+		return key
+	}
+
+	return NewIdempotencyKey()
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
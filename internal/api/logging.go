@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// logSubsystem is the tflog subsystem used for Mint API traffic. Its level follows TF_LOG_PROVIDER
+// unless overridden with TF_LOG_PROVIDER_MINT_API.
+const logSubsystem = "mint_api"
+
+var (
+	bearerTokenPattern = regexp.MustCompile(`Bearer\s+[^\s"]+`)
+	secretFieldPattern = regexp.MustCompile(`"(?:secret|token)"\s*:\s*"(?:[^"\\]|\\.)*"`)
+	valueFieldPattern  = regexp.MustCompile(`"value"\s*:\s*"(?:[^"\\]|\\.)*"`)
+)
+
+// loggingKey marks a context that already carries the Mint API logging subsystem.
+type loggingKey struct{}
+
+// maskValueFieldKey marks a context whose requests carry a sensitive variable.
+type maskValueFieldKey struct{}
+
+// withSensitiveValueField returns a context in which the "value" fields of request and response
+// bodies are masked in the logs, for variables whose value isn't known before reading it.
+func withSensitiveValueField(ctx context.Context) context.Context {
+	return context.WithValue(ctx, maskValueFieldKey{}, true)
+}
+
+// WithLogging returns a context carrying the Mint API logging subsystem, in which bearer tokens and
+// the "secret" and "token" fields of request and response bodies are masked in every log entry.
+// Set it up once for an operation, so that all of its requests log through the same subsystem;
+// requests made without it set it up themselves.
+func WithLogging(ctx context.Context) context.Context {
+	if ctx.Value(loggingKey{}) != nil {
+		return ctx
+	}
+
+	ctx = tflog.NewSubsystem(ctx, logSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_MINT_API"), tflog.WithRootFields())
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, logSubsystem, "authorization")
+	ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, logSubsystem, bearerTokenPattern, secretFieldPattern)
+	ctx = tflog.SubsystemMaskMessageRegexes(ctx, logSubsystem, bearerTokenPattern, secretFieldPattern)
+
+	return context.WithValue(ctx, loggingKey{}, true)
+}
+
+// withRequestLogging returns the logging context of a single request: the "value" fields of
+// request and response bodies for sensitive variables and any of the given sensitive values are
+// masked in addition to what WithLogging masks.
+func withRequestLogging(ctx context.Context, sensitiveValues ...string) context.Context {
+	ctx = WithLogging(ctx)
+
+	if mask, _ := ctx.Value(maskValueFieldKey{}).(bool); mask {
+		ctx = tflog.SubsystemMaskAllFieldValuesRegexes(ctx, logSubsystem, valueFieldPattern)
+		ctx = tflog.SubsystemMaskMessageRegexes(ctx, logSubsystem, valueFieldPattern)
+	}
+
+	for _, value := range sensitiveValues {
+		if value == "" {
+			continue
+		}
+
+		ctx = tflog.SubsystemMaskAllFieldValuesStrings(ctx, logSubsystem, value)
+		ctx = tflog.SubsystemMaskMessageStrings(ctx, logSubsystem, value)
+	}
+
+	return ctx
+}
+
+// logRequest writes a DEBUG entry for an outgoing request and, at TRACE, its body.
+func logRequest(ctx context.Context, req *http.Request, attempt int) {
+	fields := map[string]any{
+		"method":  req.Method,
+		"path":    req.URL.Path,
+		"attempt": attempt,
+	}
+
+	tflog.SubsystemDebug(ctx, logSubsystem, "Sending Mint API request", fields)
+
+	if req.GetBody == nil {
+		return
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+	defer body.Close() //nolint:errcheck
+
+	contents, err := io.ReadAll(body)
+	if err != nil {
+		return
+	}
+
+	fields["body"] = string(contents)
+	tflog.SubsystemTrace(ctx, logSubsystem, "Mint API request body", fields)
+}
+
+// logResponse writes a DEBUG entry for a received response and, at TRACE, its body. The response
+// body is buffered so that it can still be read by the caller afterwards.
+func logResponse(ctx context.Context, req *http.Request, resp *http.Response, attempt int, latency time.Duration) {
+	fields := map[string]any{
+		"method":     req.Method,
+		"path":       req.URL.Path,
+		"attempt":    attempt,
+		"status":     resp.StatusCode,
+		"latency_ms": latency.Milliseconds(),
+		"request_id": resp.Header.Get("X-Request-Id"),
+	}
+	if req.Header.Get(idempotencyKeyHeader) != "" {
+		fields["replayed"] = resp.Header.Get(idempotentReplayedHeader) == "true"
+	}
+
+	tflog.SubsystemDebug(ctx, logSubsystem, "Received Mint API response", fields)
+
+	contents, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint:errcheck
+	resp.Body = io.NopCloser(bytes.NewReader(contents))
+	if err != nil {
+		return
+	}
+
+	fields["body"] = string(contents)
+	tflog.SubsystemTrace(ctx, logSubsystem, "Mint API response body", fields)
+}
+
+// logRequestError writes a DEBUG entry for a request that failed before a response was received.
+func logRequestError(ctx context.Context, req *http.Request, attempt int, latency time.Duration, err error) {
+	tflog.SubsystemDebug(ctx, logSubsystem, "Mint API request failed", map[string]any{
+		"method":     req.Method,
+		"path":       req.URL.Path,
+		"attempt":    attempt,
+		"latency_ms": latency.Milliseconds(),
+		"error":      err.Error(),
+	})
+}
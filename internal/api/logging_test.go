@@ -0,0 +1,103 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+func TestClientLogsAreRedacted(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_MINT_API", "TRACE")
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		_, _ = w.Write([]byte(`{"versions":{"API_KEY":2}}`))
+	})
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	secret, err := client.SetSecretInVault(ctx, "default", Secret{Name: "API_KEY", SecretValue: "hunter2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Version != 2 {
+		t.Errorf("expected version 2, got %d", secret.Version)
+	}
+
+	logs := output.String()
+	for _, expected := range []string{`"status":200`, `"request_id":"req-123"`, `"attempt":1`, `"path":"/mint/api/vaults/secrets"`, `Mint API request body`} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("expected logs to contain %s, got:\n%s", expected, logs)
+		}
+	}
+	for _, leaked := range []string{"hunter2", "super-secret-token"} {
+		if strings.Contains(logs, leaked) {
+			t.Errorf("expected logs not to contain %q, got:\n%s", leaked, logs)
+		}
+	}
+}
+
+func TestSensitiveVariableLogsAreRedacted(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_MINT_API", "TRACE")
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"name":"ACCOUNT_ID","value":"123456789012","version":2}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":2}`))
+	})
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	if _, err := client.SetVariableInVault(ctx, "default", Variable{Name: "ACCOUNT_ID", Value: "123456789012", Sensitive: true}); err != nil {
+		t.Fatal(err)
+	}
+	variable, err := client.GetVariableInVault(ctx, "default", Variable{Name: "ACCOUNT_ID", Sensitive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if variable.Value != "123456789012" {
+		t.Errorf("expected the value to be read, got %q", variable.Value)
+	}
+
+	logs := output.String()
+	if !strings.Contains(logs, "Mint API response body") {
+		t.Errorf("expected response bodies to be logged, got:\n%s", logs)
+	}
+	if strings.Contains(logs, "123456789012") {
+		t.Errorf("expected logs not to contain the value, got:\n%s", logs)
+	}
+}
+
+func TestWithLoggingIsSetUpOnce(t *testing.T) {
+	t.Setenv("TF_LOG_PROVIDER_MINT_API", "TRACE")
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"versions":{"API_KEY":2}}`))
+	})
+
+	var output bytes.Buffer
+	ctx := WithLogging(tflogtest.RootLogger(context.Background(), &output))
+	if WithLogging(ctx) != ctx {
+		t.Error("expected a context with logging to be used as is")
+	}
+
+	if _, err := client.SetSecretInVault(ctx, "default", Secret{Name: "API_KEY", SecretValue: "hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := output.String()
+	if !strings.Contains(logs, `"@module":"provider.mint_api"`) {
+		t.Errorf("expected requests to log through the mint_api subsystem, got:\n%s", logs)
+	}
+	if strings.Contains(logs, "hunter2") {
+		t.Errorf("expected logs not to contain the secret value, got:\n%s", logs)
+	}
+}
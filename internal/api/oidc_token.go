@@ -0,0 +1,10 @@
+package api
+
+import "time"
+
+type OIDCToken struct {
+	Name      string    `json:"name"`
+	Audience  string    `json:"audience"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
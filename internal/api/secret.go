@@ -1,8 +1,46 @@
 package api
 
+import "time"
+
 type Secret struct {
 	Description string `json:"description"`
 	Name        string `json:"name"`
 	SecretValue string `json:"secret"`
 	Version     int    `json:"version"`
+
+	// ExpiresAt records when the secret stops working, such as a third-party token's expiry. Mint
+	// keeps it as metadata alongside the description; it does not remove the secret.
+	ExpiresAt *time.Time `json:"expires_at"`
+
+	// CreatedAt, UpdatedAt and UpdatedBy are set by Mint when reading a secret, UpdatedBy naming
+	// whoever last wrote it. They are never sent.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+
+	// Encoding, when "base64", tells Mint that SecretValue is base64-encoded and that the decoded
+	// bytes should be stored. JSON strings can only carry valid UTF-8, so this is how binary values
+	// are written.
+	Encoding string `json:"secret_encoding,omitempty"`
+
+	// ExpectedVersion, when set, makes a write fail with ErrConflict unless the secret is still at
+	// this version.
+	ExpectedVersion int `json:"expected_version,omitempty"`
+
+	// CreateOnly makes a write fail with ErrConflict if the secret already exists.
+	CreateOnly bool `json:"create_only,omitempty"`
+}
+
+// SecretMetadataField names a field of a secret's metadata that can be changed without its value.
+type SecretMetadataField string
+
+const (
+	SecretDescriptionField SecretMetadataField = "description"
+	SecretExpiresAtField   SecretMetadataField = "expires_at"
+)
+
+// VaultSecret is a secret's metadata along with the vault holding it.
+type VaultSecret struct {
+	VaultName string `json:"vault_name"`
+	Secret
 }
@@ -1,6 +1,30 @@
 package api
 
+import "time"
+
 type Variable struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Version int    `json:"version,omitempty"`
+
+	// CreatedAt, UpdatedAt and UpdatedBy are set by Mint when reading a variable, UpdatedBy naming
+	// whoever last wrote it. They are never sent.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty"`
+
+	// Sensitive masks the value in the API logs, along with the value field of any response when
+	// reading the variable.
+	Sensitive bool `json:"-"`
+
+	// ValueOmitted is set when reading a variable whose response had no value at all, as opposed to
+	// an empty one, so that an empty value is never assumed.
+	ValueOmitted bool `json:"-"`
+
+	// ExpectedVersion, when set, makes a write fail with ErrConflict unless the variable is still
+	// at this version.
+	ExpectedVersion int `json:"expected_version,omitempty"`
+
+	// CreateOnly makes a write fail with ErrConflict if the variable already exists.
+	CreateOnly bool `json:"create_only,omitempty"`
 }
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the ephemeral resource satisfies various framework interfaces.
+var (
+	_ ephemeral.EphemeralResource              = &AccessTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure = &AccessTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithClose     = &AccessTokenEphemeralResource{}
+)
+
+const (
+	defaultEphemeralAccessTokenName = "terraform"
+	defaultEphemeralAccessTokenTTL  = time.Hour
+)
+
+func NewAccessTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &AccessTokenEphemeralResource{}
+}
+
+// AccessTokenEphemeralResource mints a scoped Mint access token for the duration of a Terraform
+// operation and revokes it again when Terraform closes the resource.
+type AccessTokenEphemeralResource struct {
+	client api.Client
+}
+
+// AccessTokenEphemeralResourceModel describes the ephemeral resource data model.
+type AccessTokenEphemeralResourceModel struct {
+	Name      types.String `tfsdk:"name"`
+	Scopes    types.Set    `tfsdk:"scopes"`
+	Vaults    types.Set    `tfsdk:"vaults"`
+	TTL       types.String `tfsdk:"ttl"`
+	ID        types.String `tfsdk:"id"`
+	Token     types.String `tfsdk:"token"`
+	ExpiresAt types.String `tfsdk:"expires_at"`
+}
+
+func (r *AccessTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_access_token"
+}
+
+func (r *AccessTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Creates a short-lived, scoped Mint access token for the duration of a Terraform operation and revokes it when Terraform is done with it. The token is never persisted to plan or state.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Description: "A name for the token, shown in Mint's audit log. Default: terraform.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"scopes": schema.SetAttribute{
+				Description: "The permissions granted to the token, e.g. [\"vaults:read\"].",
+				ElementType: types.StringType,
+				Required:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"vaults": schema.SetAttribute{
+				Description: "Restricts the token to these vaults. When omitted the token's scopes apply to every vault the issuing token can access.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				Description: "How long the token remains valid if it is not revoked, as a duration such as \"30m\". Default: 1h.",
+				Optional:    true,
+				Validators: []validator.String{
+					positiveDurationValidator{},
+				},
+			},
+			"id": schema.StringAttribute{
+				Description: "The ID of the access token.",
+				Computed:    true,
+			},
+			"token": schema.StringAttribute{
+				Description: "The access token.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"expires_at": schema.StringAttribute{
+				Description: "The time at which the token expires, in RFC 3339 format.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *AccessTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(MintProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+func (r *AccessTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx, span := startSpan(ctx, "mint_access_token", "Open")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var data AccessTokenEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ttl := defaultEphemeralAccessTokenTTL
+	if !data.TTL.IsNull() {
+		// The value has already been checked by positiveDurationValidator.
+		ttl, _ = time.ParseDuration(data.TTL.ValueString())
+	}
+
+	token := api.AccessToken{
+		Name:      defaultEphemeralAccessTokenName,
+		ExpiresAt: time.Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+	if !data.Name.IsNull() {
+		token.Name = data.Name.ValueString()
+	}
+
+	resp.Diagnostics.Append(data.Scopes.ElementsAs(ctx, &token.Scopes, false)...)
+	resp.Diagnostics.Append(data.Vaults.ElementsAs(ctx, &token.Vaults, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token, err = r.client.CreateAccessToken(ctx, token)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating access token in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, "id", []byte(token.ID))...)
+
+	data.ID = types.StringValue(token.ID)
+	data.Token = types.StringValue(token.Token)
+	data.ExpiresAt = types.StringValue(token.ExpiresAt.UTC().Format(time.RFC3339))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+func (r *AccessTokenEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	ctx, span := startSpan(ctx, "mint_access_token", "Close")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	id, diags := req.Private.GetKey(ctx, "id")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || len(id) == 0 {
+		return
+	}
+
+	if err := r.client.RevokeAccessToken(ctx, api.AccessToken{ID: string(id)}); err != nil {
+		resp.Diagnostics.AddError(
+			"Error revoking access token in Mint",
+			fmt.Sprintf("The access token %q could not be revoked and will remain valid until it expires. Unexpected error: %s", string(id), err.Error()),
+		)
+		return
+	}
+}
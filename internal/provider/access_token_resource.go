@@ -0,0 +1,379 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the resource satisfies various framework interfaces.
+var (
+	_ resource.Resource                   = &AccessTokenResource{}
+	_ resource.ResourceWithConfigure      = &AccessTokenResource{}
+	_ resource.ResourceWithImportState    = &AccessTokenResource{}
+	_ resource.ResourceWithModifyPlan     = &AccessTokenResource{}
+	_ resource.ResourceWithValidateConfig = &AccessTokenResource{}
+)
+
+func NewAccessTokenResource() resource.Resource {
+	return &AccessTokenResource{}
+}
+
+type AccessTokenResource struct {
+	client api.Client
+}
+
+// AccessTokenResourceModel describes the resource data model.
+type AccessTokenResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	Name         types.String `tfsdk:"name"`
+	Scopes       types.Set    `tfsdk:"scopes"`
+	Vaults       types.Set    `tfsdk:"vaults"`
+	TTL          types.String `tfsdk:"ttl"`
+	ExpiresAt    types.String `tfsdk:"expires_at"`
+	RotateBefore types.String `tfsdk:"rotate_before"`
+	Token        types.String `tfsdk:"token"`
+}
+
+func (r *AccessTokenResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_access_token"
+}
+
+func (r *AccessTokenResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The ID of the access token.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "A name for the token, shown in Mint's audit log.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"scopes": schema.SetAttribute{
+				Description: "The permissions granted to the token, e.g. [\"vaults:read\"].",
+				ElementType: types.StringType,
+				Required:    true,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"vaults": schema.SetAttribute{
+				Description: "Restricts the token to these vaults. When omitted the token's scopes apply to every vault the issuing token can access.",
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"ttl": schema.StringAttribute{
+				Description: "How long each token remains valid after it is created, as a duration such as \"2160h\". Exactly one of ttl or expires_at must be set.",
+				Optional:    true,
+				Validators: []validator.String{
+					positiveDurationValidator{},
+					stringvalidator.ExactlyOneOf(path.MatchRoot("expires_at")),
+				},
+			},
+			"expires_at": schema.StringAttribute{
+				Description: "The time at which the token expires, in RFC 3339 format. Either set explicitly or computed from ttl when the token is created.",
+				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplaceIfConfigured(),
+				},
+				Validators: []validator.String{
+					rfc3339Validator{},
+				},
+			},
+			"rotate_before": schema.StringAttribute{
+				Description: "When set, the token is replaced during plan once it is within this duration of expires_at, e.g. \"168h\" to rotate a week before expiry. Requires ttl, and must be shorter than it.",
+				Optional:    true,
+				Validators: []validator.String{
+					positiveDurationValidator{},
+					stringvalidator.AlsoRequires(path.MatchRoot("ttl")),
+				},
+			},
+			"token": schema.StringAttribute{
+				Description: "The access token. Mint only reveals it when the token is created, so it is null for imported tokens.",
+				Computed:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *AccessTokenResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(MintProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+func (r *AccessTokenResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config AccessTokenResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.TTL.IsNull() || config.TTL.IsUnknown() || config.RotateBefore.IsNull() || config.RotateBefore.IsUnknown() {
+		return
+	}
+
+	// Invalid durations are reported by their validators.
+	ttl, err := time.ParseDuration(config.TTL.ValueString())
+	if err != nil {
+		return
+	}
+	rotateBefore, err := time.ParseDuration(config.RotateBefore.ValueString())
+	if err != nil {
+		return
+	}
+
+	// A token would be within rotate_before of its expiry as soon as it was created, so every plan
+	// would replace it.
+	if rotateBefore >= ttl {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotate_before"),
+			"Invalid Attribute Combination",
+			fmt.Sprintf("rotate_before (%s) must be shorter than ttl (%s).", config.RotateBefore.ValueString(), config.TTL.ValueString()),
+		)
+	}
+}
+
+func (r *AccessTokenResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.planRotation(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkReadOnlyPlan(r.client, "access token", req, resp)
+}
+
+// planRotation replaces the token once it is within rotate_before of its expiry.
+func (r *AccessTokenResource) planRotation(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var state, plan AccessTokenResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.RotateBefore.IsNull() || plan.RotateBefore.IsUnknown() || state.ExpiresAt.IsNull() {
+		return
+	}
+
+	// Both values have already been checked by their validators.
+	rotateBefore, _ := time.ParseDuration(plan.RotateBefore.ValueString())
+	expiresAt, err := time.Parse(time.RFC3339, state.ExpiresAt.ValueString())
+	if err != nil || time.Now().Before(expiresAt.Add(-rotateBefore)) {
+		return
+	}
+
+	plan.ID = types.StringUnknown()
+	plan.ExpiresAt = types.StringUnknown()
+	plan.Token = types.StringUnknown()
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	resp.RequiresReplace = append(resp.RequiresReplace, path.Root("expires_at"))
+}
+
+func (r *AccessTokenResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startSpan(ctx, "mint_access_token", "Create")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var plan AccessTokenResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token := api.AccessToken{
+		Name: plan.Name.ValueString(),
+	}
+
+	resp.Diagnostics.Append(plan.Scopes.ElementsAs(ctx, &token.Scopes, false)...)
+	resp.Diagnostics.Append(plan.Vaults.ElementsAs(ctx, &token.Vaults, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Exactly one of these has been set, and both have already been checked by their validators.
+	if !plan.TTL.IsNull() {
+		ttl, _ := time.ParseDuration(plan.TTL.ValueString())
+		token.ExpiresAt = time.Now().Add(ttl).UTC().Truncate(time.Second)
+	} else {
+		token.ExpiresAt, _ = time.Parse(time.RFC3339, plan.ExpiresAt.ValueString())
+	}
+
+	token, err = r.client.CreateAccessToken(ctx, token)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating access token in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.ID = types.StringValue(token.ID)
+	plan.Token = types.StringValue(token.Token)
+	if !plan.TTL.IsNull() {
+		plan.ExpiresAt = types.StringValue(token.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *AccessTokenResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_access_token", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var state AccessTokenResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token := api.AccessToken{
+		ID: state.ID.ValueString(),
+	}
+
+	token, err = r.client.GetAccessToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Error reading access token from Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	var diags diag.Diagnostics
+	state.Name = types.StringValue(token.Name)
+	state.Scopes, diags = types.SetValueFrom(ctx, types.StringType, token.Scopes)
+	resp.Diagnostics.Append(diags...)
+	if len(token.Vaults) > 0 {
+		state.Vaults, diags = types.SetValueFrom(ctx, types.StringType, token.Vaults)
+		resp.Diagnostics.Append(diags...)
+	} else {
+		state.Vaults = types.SetNull(types.StringType)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only overwrite the known expiry if it refers to a different instant, so that equivalent
+	// RFC 3339 spellings (e.g. time zone offsets) in the configuration don't produce a diff.
+	known, err := time.Parse(time.RFC3339, state.ExpiresAt.ValueString())
+	if err != nil || !known.Equal(token.ExpiresAt) {
+		state.ExpiresAt = types.StringValue(token.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *AccessTokenResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := startSpan(ctx, "mint_access_token", "Update")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	// Every attribute that Mint stores requires replacement, so an update only ever changes ttl or
+	// rotate_before, which the provider uses when planning future rotations.
+	var plan AccessTokenResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *AccessTokenResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startSpan(ctx, "mint_access_token", "Delete")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var state AccessTokenResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	token := api.AccessToken{
+		ID: state.ID.ValueString(),
+	}
+
+	if err = r.client.RevokeAccessToken(ctx, token); err != nil {
+		resp.Diagnostics.AddError(
+			"Error revoking access token in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *AccessTokenResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccessTokenResource(t *testing.T) {
+	// The token is within rotate_before of its expiry 30 seconds after it is created, which is long
+	// enough for the plan that follows each apply, but soon enough for a later step to rotate it.
+	config := providerConfig + `
+resource "mint_access_token" "test" {
+  name          = "terraform-provider-testing"
+  scopes        = ["vaults:read"]
+  vaults        = ["terraform_provider_testing"]
+  ttl           = "1h"
+  rotate_before = "59m30s"
+}
+`
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// rotate_before must leave the token some time before it is rotated
+			{
+				Config: providerConfig + `
+resource "mint_access_token" "test" {
+  name          = "terraform-provider-testing"
+  scopes        = ["vaults:read"]
+  vaults        = ["terraform_provider_testing"]
+  ttl           = "24h"
+  rotate_before = "48h"
+}
+`,
+				ExpectError: regexp.MustCompile(`rotate_before \(48h\) must be shorter than ttl`),
+			},
+			// Create and Read testing
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_access_token.test", "name", "terraform-provider-testing"),
+					resource.TestCheckResourceAttr("mint_access_token.test", "scopes.#", "1"),
+					resource.TestCheckResourceAttr("mint_access_token.test", "vaults.#", "1"),
+					resource.TestCheckResourceAttrWith("mint_access_token.test", "id", func(value string) error {
+						id = value
+						return nil
+					}),
+					resource.TestCheckResourceAttrSet("mint_access_token.test", "token"),
+					resource.TestCheckResourceAttrSet("mint_access_token.test", "expires_at"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:            "mint_access_token.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token", "ttl", "rotate_before"},
+			},
+			// Rotating once the token is within rotate_before of its expiry
+			{
+				PreConfig: func() { time.Sleep(45 * time.Second) },
+				Config:    config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrWith("mint_access_token.test", "id", func(value string) error {
+						if value == id {
+							return fmt.Errorf("expected the token to be rotated, but it is still %s", id)
+						}
+						return nil
+					}),
+					resource.TestCheckResourceAttrSet("mint_access_token.test", "token"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestAccessTokenRotateBefore(t *testing.T) {
+	cases := []struct {
+		ttl          string
+		rotateBefore string
+		valid        bool
+	}{
+		{ttl: "24h", rotateBefore: "1h", valid: true},
+		{ttl: "24h", rotateBefore: "24h"},
+		{ttl: "24h", rotateBefore: "48h"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ttl+"/"+c.rotateBefore, func(t *testing.T) {
+			p := newTestProvider(t)
+			config := p.config("mint_access_token", map[string]tftypes.Value{
+				"name":          tfString("terraform-provider-testing"),
+				"scopes":        tfStringSet("vaults:read"),
+				"ttl":           tfString(c.ttl),
+				"rotate_before": tfString(c.rotateBefore),
+			})
+
+			resp, err := p.server.ValidateResourceConfig(p.ctx, &tfprotov6.ValidateResourceConfigRequest{
+				TypeName: "mint_access_token",
+				Config:   p.dynamicValue(config),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.valid == hasErrors(resp.Diagnostics) {
+				t.Errorf("expected valid to be %t, got: %s", c.valid, diagnosticsString(resp.Diagnostics))
+			}
+		})
+	}
+}
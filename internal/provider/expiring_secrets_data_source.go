@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the data source satisfies various framework interfaces.
+var (
+	_ datasource.DataSource              = &ExpiringSecretsDataSource{}
+	_ datasource.DataSourceWithConfigure = &ExpiringSecretsDataSource{}
+)
+
+var expiringSecretAttrTypes = map[string]attr.Type{
+	"vault":       types.StringType,
+	"name":        types.StringType,
+	"description": types.StringType,
+	"expires_at":  types.StringType,
+	"expired":     types.BoolType,
+}
+
+func NewExpiringSecretsDataSource() datasource.DataSource {
+	return &ExpiringSecretsDataSource{}
+}
+
+type ExpiringSecretsDataSource struct {
+	client                  api.Client
+	secretExpiryWarningDays int64
+}
+
+// ExpiringSecretsDataSourceModel describes the data source data model.
+type ExpiringSecretsDataSourceModel struct {
+	Vaults     types.Set   `tfsdk:"vaults"`
+	WithinDays types.Int64 `tfsdk:"within_days"`
+	Secrets    types.List  `tfsdk:"secrets"`
+}
+
+type expiringSecretModel struct {
+	Vault       types.String `tfsdk:"vault"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	ExpiresAt   types.String `tfsdk:"expires_at"`
+	Expired     types.Bool   `tfsdk:"expired"`
+}
+
+func (d *ExpiringSecretsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_expiring_secrets"
+}
+
+func (d *ExpiringSecretsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the secrets whose expires_at has passed or falls within the next within_days, across vaults.",
+		Attributes: map[string]schema.Attribute{
+			"vaults": schema.SetAttribute{
+				Description: "The names of the vaults to check. Default: every vault the access token can read.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"within_days": schema.Int64Attribute{
+				Description: fmt.Sprintf("How many days ahead to look, at most %d. Default: the provider's secret_expiry_warning_days.", maxExpiryWindowDays),
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.AtMost(maxExpiryWindowDays),
+				},
+			},
+			"secrets": schema.ListNestedAttribute{
+				Description: "The secrets found, soonest expiry first.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"vault": schema.StringAttribute{
+							Description: "The name of the vault holding the secret.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the secret.",
+							Computed:    true,
+						},
+						"description": schema.StringAttribute{
+							Description: "The description of the secret.",
+							Computed:    true,
+						},
+						"expires_at": schema.StringAttribute{
+							Description: "When the secret expires, in RFC 3339 format.",
+							Computed:    true,
+						},
+						"expired": schema.BoolAttribute{
+							Description: "Whether expires_at has already passed.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ExpiringSecretsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(MintProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = data.Client
+	d.secretExpiryWarningDays = data.SecretExpiryWarningDays
+}
+
+func (d *ExpiringSecretsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_expiring_secrets", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var data ExpiringSecretsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var vaults []string
+	resp.Diagnostics.Append(data.Vaults.ElementsAs(ctx, &vaults, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	sort.Strings(vaults)
+
+	withinDays := d.secretExpiryWarningDays
+	if !data.WithinDays.IsNull() {
+		withinDays = data.WithinDays.ValueInt64()
+	}
+
+	now := time.Now()
+	secrets, err := d.client.ListExpiringSecrets(ctx, now.Add(time.Duration(withinDays)*24*time.Hour), vaults)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing expiring secrets in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	items := []expiringSecretModel{}
+	for _, secret := range secrets {
+		if secret.ExpiresAt == nil {
+			continue
+		}
+
+		items = append(items, expiringSecretModel{
+			Vault:       types.StringValue(secret.VaultName),
+			Name:        types.StringValue(secret.Name),
+			Description: types.StringValue(secret.Description),
+			ExpiresAt:   types.StringValue(secret.ExpiresAt.UTC().Format(time.RFC3339)),
+			Expired:     types.BoolValue(!now.Before(*secret.ExpiresAt)),
+		})
+	}
+	// Timestamps in UTC and the same format sort in time order.
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ExpiresAt.ValueString() < items[j].ExpiresAt.ValueString()
+	})
+
+	list, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: expiringSecretAttrTypes}, items)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.Secrets = list
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestExpiringSecretsDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "mint_secret" "test" {
+  vault        = "terraform_provider_testing"
+  name         = "test-expiring-secret"
+  secret_value = "foo"
+  expires_at   = "2099-01-01T00:00:00Z"
+}
+
+data "mint_expiring_secrets" "test" {
+  vaults      = [mint_secret.test.vault]
+  within_days = 36500
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.mint_expiring_secrets.test", tfjsonpath.New("secrets").AtSliceIndex(0).AtMapKey("name"), knownvalue.StringExact("test-expiring-secret")),
+					statecheck.ExpectKnownValue("data.mint_expiring_secrets.test", tfjsonpath.New("secrets").AtSliceIndex(0).AtMapKey("expired"), knownvalue.Bool(false)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,641 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// fakeMint is an in-memory stand-in for Mint's vault API, so that resources can be applied in unit
+// tests without a Mint account. Like Mint, it replays the response to a write sent with an
+// idempotency key it has already seen.
+type fakeMint struct {
+	mu sync.Mutex
+
+	now       time.Time
+	secrets   map[string]api.Secret
+	variables map[string]api.Variable
+	responses map[string]fakeResponse
+
+	// failWrites and failReads make writes to and reads from these vaults fail.
+	failWrites map[string]bool
+	failReads  map[string]bool
+	// ignoreCreateOnly makes creates overwrite existing objects, as a Mint without create_only
+	// support would.
+	ignoreCreateOnly bool
+	// loseWriteResponses applies writes but fails their responses, as when a connection drops after
+	// Mint has handled the request.
+	loseWriteResponses bool
+}
+
+type fakeResponse struct {
+	status int
+	body   []byte
+}
+
+func newFakeMint() *fakeMint {
+	return &fakeMint{
+		now:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		secrets:    map[string]api.Secret{},
+		variables:  map[string]api.Variable{},
+		responses:  map[string]fakeResponse{},
+		failWrites: map[string]bool{},
+		failReads:  map[string]bool{},
+	}
+}
+
+func (m *fakeMint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := r.Header.Get("Idempotency-Key")
+	response, replayed := m.responses[key]
+	if key == "" || !replayed {
+		recorder := httptest.NewRecorder()
+		m.serve(recorder, r)
+
+		response = fakeResponse{status: recorder.Code, body: recorder.Body.Bytes()}
+		if key != "" && response.status < 500 {
+			m.responses[key] = response
+		}
+	}
+
+	if m.loseWriteResponses && r.Method != http.MethodGet {
+		writeFakeError(w, http.StatusInternalServerError, "connection lost")
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.WriteHeader(response.status)
+	_, _ = w.Write(response.body)
+}
+
+func (m *fakeMint) serve(w http.ResponseWriter, r *http.Request) {
+	vault := r.URL.Query().Get("vault_name")
+
+	switch {
+	case r.URL.Path == "/mint/api/vaults/secrets" && r.Method == http.MethodPost:
+		var body struct {
+			Secrets   []api.Secret `json:"secrets"`
+			VaultName string       `json:"vault_name"`
+		}
+		if !decodeFakeBody(w, r, &body) || !m.writable(w, body.VaultName) {
+			return
+		}
+
+		versions := map[string]int{}
+		for _, secret := range body.Secrets {
+			prior, exists := m.secrets[body.VaultName+"/"+secret.Name]
+			if !m.accepts(w, exists, prior.Version, secret.CreateOnly, secret.ExpectedVersion) {
+				return
+			}
+
+			secret.Version, secret.CreatedAt = prior.Version+1, prior.CreatedAt
+			secret.CreateOnly, secret.ExpectedVersion = false, 0
+			m.touch(&secret.CreatedAt, &secret.UpdatedAt, &secret.UpdatedBy)
+			m.secrets[body.VaultName+"/"+secret.Name] = secret
+			versions[secret.Name] = secret.Version
+		}
+
+		writeFakeJSON(w, map[string]any{"versions": versions})
+
+	case strings.HasPrefix(r.URL.Path, "/mint/api/vaults/secrets/"):
+		name := strings.TrimPrefix(r.URL.Path, "/mint/api/vaults/secrets/")
+
+		if r.Method == http.MethodPatch {
+			var body map[string]json.RawMessage
+			if !decodeFakeBody(w, r, &body) {
+				return
+			}
+			_ = json.Unmarshal(body["vault_name"], &vault)
+			if !m.writable(w, vault) {
+				return
+			}
+
+			secret, ok := m.secrets[vault+"/"+name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			// Only the fields sent are changed.
+			if value, ok := body["description"]; ok {
+				_ = json.Unmarshal(value, &secret.Description)
+			}
+			if value, ok := body["expires_at"]; ok {
+				secret.ExpiresAt = nil
+				_ = json.Unmarshal(value, &secret.ExpiresAt)
+			}
+			m.touch(&secret.CreatedAt, &secret.UpdatedAt, &secret.UpdatedBy)
+			m.secrets[vault+"/"+name] = secret
+
+			secret.SecretValue = ""
+			writeFakeJSON(w, secret)
+			return
+		}
+
+		m.serveObject(w, r, vault, func() (any, bool) {
+			secret, ok := m.secrets[vault+"/"+name]
+			secret.SecretValue = ""
+			return secret, ok
+		}, func() { delete(m.secrets, vault+"/"+name) })
+
+	case r.URL.Path == "/mint/api/vaults/vars" && r.Method == http.MethodPost:
+		var body struct {
+			Var       api.Variable `json:"var"`
+			VaultName string       `json:"vault_name"`
+		}
+		if !decodeFakeBody(w, r, &body) || !m.writable(w, body.VaultName) {
+			return
+		}
+
+		variable := body.Var
+		prior, exists := m.variables[body.VaultName+"/"+variable.Name]
+		if !m.accepts(w, exists, prior.Version, variable.CreateOnly, variable.ExpectedVersion) {
+			return
+		}
+
+		variable.Version, variable.CreatedAt = prior.Version+1, prior.CreatedAt
+		variable.CreateOnly, variable.ExpectedVersion = false, 0
+		m.touch(&variable.CreatedAt, &variable.UpdatedAt, &variable.UpdatedBy)
+		m.variables[body.VaultName+"/"+variable.Name] = variable
+
+		writeFakeJSON(w, map[string]any{"version": variable.Version})
+
+	case strings.HasPrefix(r.URL.Path, "/mint/api/vaults/vars/"):
+		name := strings.TrimPrefix(r.URL.Path, "/mint/api/vaults/vars/")
+
+		m.serveObject(w, r, vault, func() (any, bool) {
+			variable, ok := m.variables[vault+"/"+name]
+			return variable, ok
+		}, func() { delete(m.variables, vault+"/"+name) })
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveObject reads or deletes a secret or variable.
+func (m *fakeMint) serveObject(w http.ResponseWriter, r *http.Request, vault string, get func() (any, bool), remove func()) {
+	object, ok := get()
+
+	switch r.Method {
+	case http.MethodGet:
+		if m.failReads[vault] {
+			writeFakeError(w, http.StatusInternalServerError, "reads are failing")
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		writeFakeJSON(w, object)
+
+	case http.MethodDelete:
+		if !m.writable(w, vault) {
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		remove()
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// writable fails a write to a vault whose writes are failing.
+func (m *fakeMint) writable(w http.ResponseWriter, vault string) bool {
+	if m.failWrites[vault] {
+		writeFakeError(w, http.StatusInternalServerError, "writes are failing")
+		return false
+	}
+
+	return true
+}
+
+// accepts applies the create_only and expected_version conditions of a write.
+func (m *fakeMint) accepts(w http.ResponseWriter, exists bool, version int, createOnly bool, expectedVersion int) bool {
+	if createOnly && exists && !m.ignoreCreateOnly {
+		writeFakeError(w, http.StatusConflict, "already exists")
+		return false
+	}
+	if expectedVersion != 0 && exists && version != expectedVersion {
+		writeFakeError(w, http.StatusPreconditionFailed, "version mismatch")
+		return false
+	}
+
+	return true
+}
+
+// touch records a write, advancing the clock so that every write has its own timestamp.
+func (m *fakeMint) touch(createdAt **time.Time, updatedAt **time.Time, updatedBy *string) {
+	m.now = m.now.Add(time.Minute)
+	now := m.now
+
+	if *createdAt == nil {
+		*createdAt = &now
+	}
+	*updatedAt, *updatedBy = &now, "terraform"
+}
+
+func decodeFakeBody(w http.ResponseWriter, r *http.Request, body any) bool {
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeFakeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeFakeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeFakeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// testProvider plans and applies resources through the provider's protocol server, as Terraform
+// would, against a fakeMint.
+type testProvider struct {
+	t       *testing.T
+	ctx     context.Context
+	server  tfprotov6.ProviderServer
+	schemas map[string]*tfprotov6.Schema
+	mint    *fakeMint
+}
+
+// testState is a resource's state along with its private state.
+type testState struct {
+	value   tftypes.Value
+	private []byte
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	ctx := context.Background()
+
+	mint := newFakeMint()
+	mintServer := httptest.NewTLSServer(mint)
+	t.Cleanup(mintServer.Close)
+
+	transport := http.DefaultClient.Transport
+	http.DefaultClient.Transport = mintServer.Client().Transport
+	t.Cleanup(func() { http.DefaultClient.Transport = transport })
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &testProvider{t: t, ctx: ctx, server: server, schemas: schemas.ResourceSchemas, mint: mint}
+
+	config := objectWith(schemas.Provider.ValueType(), map[string]tftypes.Value{
+		"host":         tftypes.NewValue(tftypes.String, strings.TrimPrefix(mintServer.URL, "https://")),
+		"access_token": tftypes.NewValue(tftypes.String, "test-token"),
+	})
+	resp, err := server.ConfigureProvider(ctx, &tfprotov6.ConfigureProviderRequest{Config: p.dynamicValue(config)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.requireNoErrors(resp.Diagnostics)
+
+	return p
+}
+
+// config returns the configuration of a resource with the given attributes, all others null.
+func (p *testProvider) config(typeName string, attributes map[string]tftypes.Value) tftypes.Value {
+	return objectWith(p.schemas[typeName].ValueType(), attributes)
+}
+
+// apply plans and applies a resource's configuration over its prior state, replacing the resource
+// if the plan requires it, and returns the new state along with every diagnostic.
+func (p *testProvider) apply(typeName string, prior testState, config tftypes.Value) (testState, []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+
+	objectType := p.schemas[typeName].ValueType()
+	if prior.value.Type() == nil {
+		prior.value = tftypes.NewValue(objectType, nil)
+	}
+
+	plan, err := p.server.PlanResourceChange(p.ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(prior.value),
+		ProposedNewState: p.dynamicValue(p.proposedNewState(typeName, prior.value, config)),
+		Config:           p.dynamicValue(config),
+		PriorPrivate:     prior.private,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(plan.Diagnostics) {
+		return prior, plan.Diagnostics
+	}
+
+	if len(plan.RequiresReplace) > 0 && !prior.value.IsNull() {
+		diags := p.destroy(typeName, prior)
+		if hasErrors(diags) {
+			return prior, diags
+		}
+
+		return p.apply(typeName, testState{}, config)
+	}
+
+	planned, err := plan.PlannedState.Unmarshal(objectType)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	applied, err := p.server.ApplyResourceChange(p.ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     p.dynamicValue(prior.value),
+		PlannedState:   plan.PlannedState,
+		Config:         p.dynamicValue(config),
+		PlannedPrivate: plan.PlannedPrivate,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	next, err := applied.NewState.Unmarshal(objectType)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	// Terraform rejects a result that contradicts a value known when planning.
+	if !hasErrors(applied.Diagnostics) {
+		p.checkConsistent(planned, next)
+	}
+
+	return testState{value: next, private: applied.Private}, append(plan.Diagnostics, applied.Diagnostics...)
+}
+
+// read refreshes a resource's state. The state is null if the resource no longer exists.
+func (p *testProvider) read(typeName string, state testState) testState {
+	p.t.Helper()
+
+	resp, err := p.server.ReadResource(p.ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     typeName,
+		CurrentState: p.dynamicValue(state.value),
+		Private:      state.private,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.requireNoErrors(resp.Diagnostics)
+
+	value, err := resp.NewState.Unmarshal(p.schemas[typeName].ValueType())
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	return testState{value: value, private: resp.Private}
+}
+
+// importState imports a resource by ID and refreshes it, as terraform import does.
+func (p *testProvider) importState(typeName string, id string) testState {
+	p.t.Helper()
+
+	resp, err := p.server.ImportResourceState(p.ctx, &tfprotov6.ImportResourceStateRequest{
+		TypeName: typeName,
+		ID:       id,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.requireNoErrors(resp.Diagnostics)
+
+	imported := resp.ImportedResources[0]
+	value, err := imported.State.Unmarshal(p.schemas[typeName].ValueType())
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	return p.read(typeName, testState{value: value, private: imported.Private})
+}
+
+// move moves the raw state of another of the provider's resources to a resource, as a moved block
+// does.
+func (p *testProvider) move(typeName string, sourceTypeName string, sourceState string) testState {
+	p.t.Helper()
+
+	resp, err := p.server.MoveResourceState(p.ctx, &tfprotov6.MoveResourceStateRequest{
+		SourceProviderAddress: "registry.terraform.io/rwx-research/mint",
+		SourceTypeName:        sourceTypeName,
+		SourceState:           &tfprotov6.RawState{JSON: []byte(sourceState)},
+		TargetTypeName:        typeName,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.requireNoErrors(resp.Diagnostics)
+
+	value, err := resp.TargetState.Unmarshal(p.schemas[typeName].ValueType())
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	return testState{value: value, private: resp.TargetPrivate}
+}
+
+// destroy plans and applies the deletion of a resource.
+func (p *testProvider) destroy(typeName string, prior testState) []*tfprotov6.Diagnostic {
+	p.t.Helper()
+
+	none := p.dynamicValue(tftypes.NewValue(p.schemas[typeName].ValueType(), nil))
+
+	plan, err := p.server.PlanResourceChange(p.ctx, &tfprotov6.PlanResourceChangeRequest{
+		TypeName:         typeName,
+		PriorState:       p.dynamicValue(prior.value),
+		ProposedNewState: none,
+		Config:           none,
+		PriorPrivate:     prior.private,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	if hasErrors(plan.Diagnostics) {
+		return plan.Diagnostics
+	}
+
+	applied, err := p.server.ApplyResourceChange(p.ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:       typeName,
+		PriorState:     p.dynamicValue(prior.value),
+		PlannedState:   none,
+		Config:         none,
+		PlannedPrivate: plan.PlannedPrivate,
+	})
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	return append(plan.Diagnostics, applied.Diagnostics...)
+}
+
+// proposedNewState merges a configuration with the prior state as Terraform does before planning:
+// computed attributes left out of the configuration keep their prior values.
+func (p *testProvider) proposedNewState(typeName string, prior tftypes.Value, config tftypes.Value) tftypes.Value {
+	var priorAttributes, configAttributes map[string]tftypes.Value
+	if !prior.IsNull() {
+		_ = prior.As(&priorAttributes)
+	}
+	_ = config.As(&configAttributes)
+
+	// As returns the value's own map, which must not be changed.
+	attributes := make(map[string]tftypes.Value, len(configAttributes))
+	for name, value := range configAttributes {
+		attributes[name] = value
+	}
+
+	objectType := p.schemas[typeName].ValueType().(tftypes.Object)
+	for _, attribute := range p.schemas[typeName].Block.Attributes {
+		if attribute.Computed && attributes[attribute.Name].IsNull() {
+			attributes[attribute.Name] = tftypes.NewValue(objectType.AttributeTypes[attribute.Name], nil)
+			if value, ok := priorAttributes[attribute.Name]; ok {
+				attributes[attribute.Name] = value
+			}
+		}
+	}
+
+	return tftypes.NewValue(objectType, attributes)
+}
+
+// checkConsistent fails the test for each attribute whose applied value differs from a known planned
+// value, which Terraform reports as "Provider produced inconsistent result after apply".
+func (p *testProvider) checkConsistent(planned tftypes.Value, next tftypes.Value) {
+	p.t.Helper()
+
+	var plannedAttributes, nextAttributes map[string]tftypes.Value
+	_ = planned.As(&plannedAttributes)
+	_ = next.As(&nextAttributes)
+
+	for name, value := range plannedAttributes {
+		if value.IsFullyKnown() && !sameValue(value, nextAttributes[name]) {
+			p.t.Errorf("inconsistent result after apply: %s was planned as %v but applied as %v", name, value, nextAttributes[name])
+		}
+	}
+}
+
+func (p *testProvider) dynamicValue(value tftypes.Value) *tfprotov6.DynamicValue {
+	p.t.Helper()
+
+	dynamicValue, err := tfprotov6.NewDynamicValue(value.Type(), value)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+
+	return &dynamicValue
+}
+
+func (p *testProvider) requireNoErrors(diags []*tfprotov6.Diagnostic) {
+	p.t.Helper()
+
+	if hasErrors(diags) {
+		p.t.Fatalf("unexpected diagnostics: %s", diagnosticsString(diags))
+	}
+}
+
+// objectWith returns an object of the given type with the given attributes, all others null.
+func objectWith(objectType tftypes.Type, attributes map[string]tftypes.Value) tftypes.Value {
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.(tftypes.Object).AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+		if value, ok := attributes[name]; ok {
+			values[name] = value
+		}
+	}
+
+	return tftypes.NewValue(objectType, values)
+}
+
+// stateAttribute returns an attribute of a resource's state.
+func stateAttribute(state testState, name string) tftypes.Value {
+	var attributes map[string]tftypes.Value
+	_ = state.value.As(&attributes)
+
+	return attributes[name]
+}
+
+// sameValue compares values, ignoring the order of set elements.
+func sameValue(a tftypes.Value, b tftypes.Value) bool {
+	if a.Type().Is(tftypes.Set{}) && b.Type().Is(tftypes.Set{}) && !a.IsNull() && !b.IsNull() {
+		var as, bs []tftypes.Value
+		_ = a.As(&as)
+		_ = b.As(&bs)
+
+		if len(as) != len(bs) {
+			return false
+		}
+		for _, element := range as {
+			found := false
+			for _, other := range bs {
+				found = found || element.Equal(other)
+			}
+			if !found {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return a.Equal(b)
+}
+
+func tfString(value string) tftypes.Value {
+	return tftypes.NewValue(tftypes.String, value)
+}
+
+func tfStringSet(values ...string) tftypes.Value {
+	elements := make([]tftypes.Value, len(values))
+	for i, value := range values {
+		elements[i] = tfString(value)
+	}
+
+	return tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, elements)
+}
+
+func hasErrors(diags []*tfprotov6.Diagnostic) bool {
+	for _, diag := range diags {
+		if diag.Severity == tfprotov6.DiagnosticSeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+func diagnosticsString(diags []*tfprotov6.Diagnostic) string {
+	var messages []string
+	for _, diag := range diags {
+		messages = append(messages, diag.Summary+": "+diag.Detail)
+	}
+
+	return strings.Join(messages, "; ")
+}
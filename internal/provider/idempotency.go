@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// privateIdempotencyKey holds the idempotency key of an update that has not yet been confirmed.
+const privateIdempotencyKey = "idempotency_key"
+
+// pendingWrite is persisted in private state while an update is in flight.
+type pendingWrite struct {
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// fingerprintWrite returns a digest identifying the content of a write, so that a retry of the
+// same write can be told apart from a different one.
+func fingerprintWrite(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// updateIdempotencyKey returns the idempotency key for an update. A key persisted by an earlier,
+// unconfirmed attempt at the same write is reused; otherwise a new key is generated. The key is
+// recorded in the response's private state before the write is sent so that it survives a failed
+// apply.
+func updateIdempotencyKey(ctx context.Context, prior privateGetter, next privateSetter, fingerprint string) (string, diag.Diagnostics) {
+	return updateIdempotencyKeyAt(ctx, prior, next, privateIdempotencyKey, fingerprint)
+}
+
+// updateIdempotencyKeyAt is updateIdempotencyKey for a pending write recorded under privateKey, for
+// resources that make several independent writes in one apply.
+func updateIdempotencyKeyAt(ctx context.Context, prior privateGetter, next privateSetter, privateKey string, fingerprint string) (string, diag.Diagnostics) {
+	key, ok := pendingIdempotencyKeyAt(ctx, prior, privateKey, fingerprint)
+	if !ok {
+		key = api.NewIdempotencyKey()
+	}
+
+	encoded, err := json.Marshal(pendingWrite{Key: key, Fingerprint: fingerprint})
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to record idempotency key", "Unexpected error: "+err.Error())
+		return "", diags
+	}
+
+	return key, next.SetKey(ctx, privateKey, encoded)
+}
+
+// pendingIdempotencyKeyAt returns the key of an unconfirmed write recorded under privateKey, if it
+// was the same write.
+func pendingIdempotencyKeyAt(ctx context.Context, prior privateGetter, privateKey string, fingerprint string) (string, bool) {
+	var pending pendingWrite
+
+	value, diags := prior.GetKey(ctx, privateKey)
+	if diags.HasError() || value == nil || json.Unmarshal(value, &pending) != nil || pending.Fingerprint != fingerprint {
+		return "", false
+	}
+
+	return pending.Key, pending.Key != ""
+}
+
+// clearIdempotencyKey forgets the pending update once Mint has confirmed it.
+func clearIdempotencyKey(ctx context.Context, next privateSetter) diag.Diagnostics {
+	return clearIdempotencyKeyAt(ctx, next, privateIdempotencyKey)
+}
+
+// clearIdempotencyKeyAt is clearIdempotencyKey for a pending write recorded under privateKey.
+func clearIdempotencyKeyAt(ctx context.Context, next privateSetter, privateKey string) diag.Diagnostics {
+	return next.SetKey(ctx, privateKey, nil)
+}
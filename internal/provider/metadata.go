@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// resourceID identifies a secret or variable by its vaults and name, as "vault/name". A secret
+// written to several vaults lists them all, separated by commas.
+func resourceID(vaults []string, name string) types.String {
+	return types.StringValue(strings.Join(vaults, ",") + "/" + name)
+}
+
+// timestampValue returns a timestamp set by Mint in RFC 3339 format, or null if Mint didn't set it.
+func timestampValue(t *time.Time) types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+
+	return types.StringValue(t.UTC().Format(time.RFC3339))
+}
+
+// plannedCreatedAt returns the created_at of a secret or variable whose timestamps could not be
+// read back after writing it. An update plans created_at from state, and Terraform requires the
+// applied value to match, so a known one is kept; an unknown one becomes null.
+func plannedCreatedAt(planned types.String) types.String {
+	if planned.IsUnknown() {
+		return types.StringNull()
+	}
+
+	return planned
+}
+
+// updatedByValue returns who last wrote a secret or variable, or null if Mint didn't say.
+func updatedByValue(updatedBy string) types.String {
+	if updatedBy == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(updatedBy)
+}
+
+// timestamps accumulates the timestamps of a secret across the vaults holding it: it was created
+// when it was first created in any of them, and updated when it was last updated in any of them.
+type timestamps struct {
+	createdAt *time.Time
+	updatedAt *time.Time
+	updatedBy string
+}
+
+// add includes the timestamps of the secret in another vault.
+func (t *timestamps) add(createdAt *time.Time, updatedAt *time.Time, updatedBy string) {
+	if createdAt != nil && (t.createdAt == nil || createdAt.Before(*t.createdAt)) {
+		t.createdAt = createdAt
+	}
+	if updatedAt != nil && (t.updatedAt == nil || updatedAt.After(*t.updatedAt)) {
+		t.updatedAt, t.updatedBy = updatedAt, updatedBy
+	}
+}
+
+// values returns the created_at, updated_at and updated_by attributes.
+func (t timestamps) values() (types.String, types.String, types.String) {
+	return timestampValue(t.createdAt), timestampValue(t.updatedAt), updatedByValue(t.updatedBy)
+}
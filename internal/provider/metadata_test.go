@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestamps(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2030, 1, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+
+	var ts timestamps
+	ts.add(day(2), day(5), "ana@example.com")
+	ts.add(day(1), day(3), "ben@example.com")
+	ts.add(nil, nil, "")
+
+	createdAt, updatedAt, updatedBy := ts.values()
+	if createdAt.ValueString() != "2030-01-01T00:00:00Z" {
+		t.Errorf("expected the earliest created_at, got %s", createdAt)
+	}
+	if updatedAt.ValueString() != "2030-01-05T00:00:00Z" || updatedBy.ValueString() != "ana@example.com" {
+		t.Errorf("expected the latest update, got %s by %s", updatedAt, updatedBy)
+	}
+
+	createdAt, updatedAt, updatedBy = timestamps{}.values()
+	if !createdAt.IsNull() || !updatedAt.IsNull() || !updatedBy.IsNull() {
+		t.Errorf("expected null timestamps, got %s, %s and %s", createdAt, updatedAt, updatedBy)
+	}
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the ephemeral resource satisfies various framework interfaces.
+var (
+	_ ephemeral.EphemeralResource              = &OIDCTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure = &OIDCTokenEphemeralResource{}
+)
+
+func NewOIDCTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &OIDCTokenEphemeralResource{}
+}
+
+// OIDCTokenEphemeralResource issues an OIDC token for one of a vault's OIDC configurations. The
+// token is a signed JWT which Mint can neither renew nor revoke, so it simply expires on its own
+// and the resource implements neither Renew nor Close.
+type OIDCTokenEphemeralResource struct {
+	client api.Client
+}
+
+// OIDCTokenEphemeralResourceModel describes the ephemeral resource data model.
+type OIDCTokenEphemeralResourceModel struct {
+	Vault     types.String `tfsdk:"vault"`
+	Name      types.String `tfsdk:"name"`
+	Audience  types.String `tfsdk:"audience"`
+	Token     types.String `tfsdk:"token"`
+	ExpiresAt types.String `tfsdk:"expires_at"`
+}
+
+func (r *OIDCTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_oidc_token"
+}
+
+func (r *OIDCTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Issues a short-lived OIDC token from one of a Mint vault's OIDC token configurations, for example to assume a cloud role from another provider's configuration. The token is never persisted to plan or state.",
+		Attributes: map[string]schema.Attribute{
+			"vault": schema.StringAttribute{
+				Description: "The name of the vault in Mint that holds the OIDC token configuration.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
+						"can only include alphanumeric characters, dashes, or underscores",
+					),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the OIDC token configuration in the vault.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"audience": schema.StringAttribute{
+				Description: "The audience the token was issued for, as configured in the vault.",
+				Computed:    true,
+			},
+			"token": schema.StringAttribute{
+				Description: "The OIDC token (a signed JWT).",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"expires_at": schema.StringAttribute{
+				Description: "The time at which the token expires, in RFC 3339 format.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (r *OIDCTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(MintProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Ephemeral Resource Configure Type",
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+func (r *OIDCTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	ctx, span := startSpan(ctx, "mint_oidc_token", "Open")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var data OIDCTokenEphemeralResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vault := data.Vault.ValueString()
+	span.SetAttributes(tracing.VaultKey.String(vault))
+	token := api.OIDCToken{
+		Name: data.Name.ValueString(),
+	}
+
+	token, err = r.client.CreateOIDCTokenInVault(ctx, vault, token)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			resp.Diagnostics.AddError(
+				"OIDC token configuration not found",
+				fmt.Sprintf("Vault %q does not contain an OIDC token configuration named %q", vault, data.Name.ValueString()),
+			)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Error issuing OIDC token from Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	data.Audience = types.StringValue(token.Audience)
+	data.Token = types.StringValue(token.Token)
+	data.ExpiresAt = types.StringValue(token.ExpiresAt.UTC().Format(time.RFC3339))
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Keys used to store provider-only data in a resource's private state.
+const (
+	// privateVersionKey holds the version of the remote object last written by the provider.
+	privateVersionKey = "version"
+	// privateObservedVersionKey holds the version of the remote object seen during the last refresh.
+	privateObservedVersionKey = "observed_version"
+)
+
+// privateGetter is satisfied by the private state exposed on framework requests.
+type privateGetter interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// privateSetter is satisfied by the private state exposed on framework responses.
+type privateSetter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// getPrivateInt reads an integer from private state, returning 0 if the key is missing or malformed.
+func getPrivateInt(ctx context.Context, private privateGetter, key string) int {
+	value, diags := private.GetKey(ctx, key)
+	if diags.HasError() || value == nil {
+		return 0
+	}
+
+	i, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0
+	}
+
+	return i
+}
+
+// setPrivateInt stores an integer in private state.
+func setPrivateInt(ctx context.Context, private privateSetter, key string, value int) diag.Diagnostics {
+	return private.SetKey(ctx, key, []byte(strconv.Itoa(value)))
+}
@@ -2,20 +2,31 @@ package provider
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/rwx-research/terraform-provider-mint/internal/api"
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure MintProvider satisfies various the provider interface.
-var _ provider.Provider = &MintProvider{}
+var (
+	_ provider.Provider                       = &MintProvider{}
+	_ provider.ProviderWithEphemeralResources = &MintProvider{}
+	_ provider.ProviderWithFunctions          = &MintProvider{}
+)
 
 type MintProvider struct {
 	version string
@@ -23,8 +34,32 @@ type MintProvider struct {
 
 // MintProviderModel describes the provider data model.
 type MintProviderModel struct {
-	Host        types.String `tfsdk:"host"`
-	AccessToken types.String `tfsdk:"access_token"`
+	Host                    types.String `tfsdk:"host"`
+	AccessToken             types.String `tfsdk:"access_token"`
+	ReadOnly                types.Bool   `tfsdk:"read_only"`
+	RunDefinitionsDirectory types.String `tfsdk:"run_definitions_directory"`
+	SecretExpiryWarningDays types.Int64  `tfsdk:"secret_expiry_warning_days"`
+}
+
+// defaultSecretExpiryWarningDays is how long before a secret's expires_at plans start warning.
+const defaultSecretExpiryWarningDays = 30
+
+// maxExpiryWindowDays bounds how many days ahead expiry is checked, so that the window always fits
+// in a time.Duration.
+const maxExpiryWindowDays = 36500
+
+// MintProviderData is passed to every data source, ephemeral resource and resource when the
+// provider is configured.
+type MintProviderData struct {
+	Client api.Client
+
+	// RunDefinitionsDirectory, when set, is scanned for references to secrets and variables that
+	// a plan would destroy.
+	RunDefinitionsDirectory string
+
+	// SecretExpiryWarningDays is how many days before a secret's expires_at plans start warning
+	// about it.
+	SecretExpiryWarningDays int64
 }
 
 func (p *MintProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -44,11 +79,30 @@ func (p *MintProvider) Schema(ctx context.Context, req provider.SchemaRequest, r
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"read_only": schema.BoolAttribute{
+				Description: "When true, the provider refuses to create, update, or delete anything in Mint and reports an error at plan time if changes are proposed. Useful for audits and plan-only pipelines. This may also be provided via the MINT_READ_ONLY environment variable.",
+				Optional:    true,
+			},
+			"run_definitions_directory": schema.StringAttribute{
+				Description: "A directory of Mint run definitions, such as .mint, relative to the working directory. When set, plans that destroy a secret or variable still referenced by one of these run definitions include a warning. This may also be provided via the MINT_RUN_DEFINITIONS_DIRECTORY environment variable.",
+				Optional:    true,
+			},
+			"secret_expiry_warning_days": schema.Int64Attribute{
+				Description: fmt.Sprintf("How many days before a secret's expires_at plans start warning about it, at most %d. This is also the default window of the mint_expiring_secrets data source. Default: %d. This may also be provided via the MINT_SECRET_EXPIRY_WARNING_DAYS environment variable.", maxExpiryWindowDays, defaultSecretExpiryWarningDays),
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+					int64validator.AtMost(maxExpiryWindowDays),
+				},
+			},
 		},
 	}
 }
 
 func (p *MintProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	_, span := tracing.Start(ctx, "MintProvider.Configure")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	var config MintProviderModel
 
 	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
@@ -72,6 +126,30 @@ func (p *MintProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 				"Either target apply the source of the value first, set the value statically in the configuration, or use the RWX_ACCESS_TOKEN environment variable.",
 		)
 	}
+	if config.ReadOnly.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("read_only"),
+			"Unknown Mint Read-Only Mode",
+			"The provider cannot create the Mint API client as there is an unknown configuration value for read-only mode. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the MINT_READ_ONLY environment variable.",
+		)
+	}
+	if config.RunDefinitionsDirectory.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("run_definitions_directory"),
+			"Unknown Mint Run Definitions Directory",
+			"The provider cannot be configured as there is an unknown configuration value for the run definitions directory. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the MINT_RUN_DEFINITIONS_DIRECTORY environment variable.",
+		)
+	}
+	if config.SecretExpiryWarningDays.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("secret_expiry_warning_days"),
+			"Unknown Mint Secret Expiry Warning Days",
+			"The provider cannot be configured as there is an unknown configuration value for the secret expiry warning days. "+
+				"Either target apply the source of the value first, set the value statically in the configuration, or use the MINT_SECRET_EXPIRY_WARNING_DAYS environment variable.",
+		)
+	}
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -86,6 +164,42 @@ func (p *MintProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		accessToken = config.AccessToken.ValueString()
 	}
 
+	readOnly := false
+	if env := os.Getenv("MINT_READ_ONLY"); env != "" {
+		var err error
+		if readOnly, err = strconv.ParseBool(env); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("read_only"),
+				"Invalid Mint Read-Only Mode",
+				"The MINT_READ_ONLY environment variable must be a boolean such as \"true\" or \"false\", got: "+env,
+			)
+		}
+	}
+	if !config.ReadOnly.IsNull() {
+		readOnly = config.ReadOnly.ValueBool()
+	}
+
+	runDefinitionsDirectory := os.Getenv("MINT_RUN_DEFINITIONS_DIRECTORY")
+	if !config.RunDefinitionsDirectory.IsNull() {
+		runDefinitionsDirectory = config.RunDefinitionsDirectory.ValueString()
+	}
+
+	secretExpiryWarningDays := int64(defaultSecretExpiryWarningDays)
+	if env := os.Getenv("MINT_SECRET_EXPIRY_WARNING_DAYS"); env != "" {
+		var err error
+		if secretExpiryWarningDays, err = strconv.ParseInt(env, 10, 64); err != nil ||
+			secretExpiryWarningDays < 0 || secretExpiryWarningDays > maxExpiryWindowDays {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("secret_expiry_warning_days"),
+				"Invalid Mint Secret Expiry Warning Days",
+				fmt.Sprintf("The MINT_SECRET_EXPIRY_WARNING_DAYS environment variable must be a whole number of days from 0 to %d, got: %s", maxExpiryWindowDays, env),
+			)
+		}
+	}
+	if !config.SecretExpiryWarningDays.IsNull() {
+		secretExpiryWarningDays = config.SecretExpiryWarningDays.ValueInt64()
+	}
+
 	if host == "" {
 		host = "cloud.rwx.com"
 	}
@@ -102,7 +216,7 @@ func (p *MintProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		return
 	}
 
-	client, err := api.NewClient(api.Config{Host: host, AccessToken: accessToken, Version: p.version})
+	client, err := api.NewClient(api.Config{Host: host, AccessToken: accessToken, Version: p.version, ReadOnly: readOnly})
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create Mint API client",
@@ -112,19 +226,45 @@ func (p *MintProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 		)
 	}
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	data := MintProviderData{
+		Client:                  client,
+		RunDefinitionsDirectory: runDefinitionsDirectory,
+		SecretExpiryWarningDays: secretExpiryWarningDays,
+	}
+
+	resp.DataSourceData = data
+	resp.EphemeralResourceData = data
+	resp.ResourceData = data
 }
 
 func (p *MintProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
+		NewAccessTokenResource,
 		NewSecretResource,
+		NewSSHDeployKeyResource,
 		NewVariableResource,
 	}
 }
 
+func (p *MintProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewAccessTokenEphemeralResource,
+		NewOIDCTokenEphemeralResource,
+	}
+}
+
 func (p *MintProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return nil
+	return []func() datasource.DataSource{
+		NewExpiringSecretsDataSource,
+		NewRunDefinitionDataSource,
+		NewRunDefinitionReferencesDataSource,
+	}
+}
+
+func (p *MintProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewValidateRunDefinitionFunction,
+	}
 }
 
 func New(version string) func() provider.Provider {
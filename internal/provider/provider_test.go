@@ -3,6 +3,7 @@ package provider
 import (
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
 )
 
 const (
@@ -25,4 +26,11 @@ var (
 	testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
 		"mint": providerserver.NewProtocol6WithError(New("test")()),
 	}
+
+	// testAccProtoV6ProviderFactoriesWithEcho additionally includes the echo provider, which
+	// exposes the values of ephemeral resources in state so that acceptance tests can check them.
+	testAccProtoV6ProviderFactoriesWithEcho = map[string]func() (tfprotov6.ProviderServer, error){
+		"mint": providerserver.NewProtocol6WithError(New("test")()),
+		"echo": echoprovider.NewProviderServer(),
+	}
 )
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+)
+
+// checkReadOnlyPlan adds an error to the plan when the provider is configured as read-only and the
+// plan would create, update, or destroy the resource. This surfaces accidental changes at plan
+// time rather than partway through an apply.
+func checkReadOnlyPlan(client api.Client, kind string, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !client.ReadOnly {
+		return
+	}
+
+	var action string
+	switch {
+	case req.State.Raw.IsNull():
+		action = "create"
+	case req.Plan.Raw.IsNull():
+		action = "destroy"
+	case !resp.Plan.Raw.Equal(req.State.Raw):
+		action = "update"
+	default:
+		return
+	}
+
+	resp.Diagnostics.AddError(
+		"Mint provider is read-only",
+		fmt.Sprintf("This plan would %s a %s, but the provider is configured with read_only = true. "+
+			"Remove the change from the configuration or disable read-only mode to apply it.", action, kind),
+	)
+}
@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestReadOnlyProvider(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Planning a create is rejected before anything is sent to Mint
+			{
+				Config: `
+provider "mint" {
+  read_only = true
+}
+
+resource "mint_variable" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-read-only-var"
+  value = "foo"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Mint provider is read-only`),
+			},
+		},
+	})
+}
@@ -0,0 +1,492 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the data source satisfies the framework interface.
+var _ datasource.DataSource = &RunDefinitionDataSource{}
+
+func NewRunDefinitionDataSource() datasource.DataSource {
+	return &RunDefinitionDataSource{}
+}
+
+// RunDefinitionDataSource renders a run definition from typed blocks. Like
+// RunDefinitionReferencesDataSource, it works entirely locally.
+type RunDefinitionDataSource struct{}
+
+// RunDefinitionDataSourceModel describes the data source data model.
+type RunDefinitionDataSourceModel struct {
+	ToolCache        *runDefinitionToolCacheModel        `tfsdk:"tool_cache"`
+	On               *runDefinitionOnModel               `tfsdk:"on"`
+	ConcurrencyPools []runDefinitionConcurrencyPoolModel `tfsdk:"concurrency_pool"`
+	Base             *runDefinitionBaseModel             `tfsdk:"base"`
+	Tasks            []runDefinitionTaskModel            `tfsdk:"task"`
+	YAML             types.String                        `tfsdk:"yaml"`
+}
+
+type runDefinitionToolCacheModel struct {
+	Vault string `tfsdk:"vault"`
+}
+
+type runDefinitionOnModel struct {
+	GitHub *runDefinitionGitHubModel       `tfsdk:"github"`
+	Cron   []runDefinitionCronTriggerModel `tfsdk:"cron"`
+}
+
+type runDefinitionGitHubModel struct {
+	Push        []runDefinitionGitHubTriggerModel `tfsdk:"push"`
+	PullRequest []runDefinitionGitHubTriggerModel `tfsdk:"pull_request"`
+}
+
+type runDefinitionGitHubTriggerModel struct {
+	If     types.String      `tfsdk:"if"`
+	Init   map[string]string `tfsdk:"init"`
+	Target []string          `tfsdk:"target"`
+}
+
+type runDefinitionCronTriggerModel struct {
+	Key      string            `tfsdk:"key"`
+	Schedule string            `tfsdk:"schedule"`
+	If       types.String      `tfsdk:"if"`
+	Init     map[string]string `tfsdk:"init"`
+	Target   []string          `tfsdk:"target"`
+}
+
+type runDefinitionConcurrencyPoolModel struct {
+	ID         string       `tfsdk:"id"`
+	Capacity   int64        `tfsdk:"capacity"`
+	OnOverflow types.String `tfsdk:"on_overflow"`
+}
+
+type runDefinitionBaseModel struct {
+	OS   string       `tfsdk:"os"`
+	Tag  string       `tfsdk:"tag"`
+	Arch types.String `tfsdk:"arch"`
+}
+
+type runDefinitionTaskModel struct {
+	Key         string                          `tfsdk:"key"`
+	Call        types.String                    `tfsdk:"call"`
+	Use         []string                        `tfsdk:"use"`
+	After       []string                        `tfsdk:"after"`
+	If          types.String                    `tfsdk:"if"`
+	Run         types.String                    `tfsdk:"run"`
+	With        map[string]string               `tfsdk:"with"`
+	Filter      []string                        `tfsdk:"filter"`
+	Env         map[string]string               `tfsdk:"env"`
+	EnvSecret   []runDefinitionEnvSecretModel   `tfsdk:"env_secret"`
+	EnvVariable []runDefinitionEnvVariableModel `tfsdk:"env_variable"`
+	ToolCache   types.String                    `tfsdk:"tool_cache"`
+}
+
+type runDefinitionEnvSecretModel struct {
+	Name   string `tfsdk:"name"`
+	Vault  string `tfsdk:"vault"`
+	Secret string `tfsdk:"secret"`
+}
+
+type runDefinitionEnvVariableModel struct {
+	Name     string `tfsdk:"name"`
+	Vault    string `tfsdk:"vault"`
+	Variable string `tfsdk:"variable"`
+}
+
+func (d *RunDefinitionDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_run_definition"
+}
+
+func (d *RunDefinitionDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	vaultNameValidators := []validator.String{
+		stringvalidator.LengthAtLeast(1),
+		stringvalidator.RegexMatches(
+			regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
+			"can only include alphanumeric characters, dashes, or underscores",
+		),
+	}
+	envVarNameValidators := []validator.String{
+		stringvalidator.RegexMatches(
+			regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`),
+			"must start with a letter or underscore and only include alphanumeric characters or underscores",
+		),
+	}
+	triggerAttributes := func() map[string]schema.Attribute {
+		return map[string]schema.Attribute{
+			"if": schema.StringAttribute{
+				Description: "An expression which must be true for the trigger to start a run.",
+				Optional:    true,
+			},
+			"init": schema.MapAttribute{
+				Description: "Init parameters passed to the run.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"target": schema.ListAttribute{
+				Description: "The keys of the tasks to run. Defaults to every task.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+		}
+	}
+	githubTriggerBlock := func(description string) schema.ListNestedBlock {
+		return schema.ListNestedBlock{
+			Description:  description,
+			NestedObject: schema.NestedBlockObject{Attributes: triggerAttributes()},
+		}
+	}
+
+	cronAttributes := triggerAttributes()
+	cronAttributes["key"] = schema.StringAttribute{
+		Description: "A unique key for the cron trigger.",
+		Required:    true,
+	}
+	cronAttributes["schedule"] = schema.StringAttribute{
+		Description: "The schedule in cron syntax, optionally followed by a time zone, such as \"0 7 * * 1-5 America/New_York\".",
+		Required:    true,
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Renders a Mint run definition from typed blocks, producing canonical YAML that can be written to the repository with local_file. The result is checked the same way as provider::mint::validate_run_definition, so mistakes such as a task using an undefined task are reported before a run ever starts. Nothing is read from Mint.",
+		Attributes: map[string]schema.Attribute{
+			"yaml": schema.StringAttribute{
+				Description: "The rendered run definition.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"tool_cache": schema.SingleNestedBlock{
+				Description: "Where the tool cache for runs of this definition is stored.",
+				Validators: []validator.Object{
+					objectvalidator.AlsoRequires(path.MatchRelative().AtName("vault")),
+				},
+				Attributes: map[string]schema.Attribute{
+					"vault": schema.StringAttribute{
+						Description: "The name of the vault that holds the tool cache. Required when the block is present.",
+						Optional:    true,
+						Validators:  vaultNameValidators,
+					},
+				},
+			},
+			"on": schema.SingleNestedBlock{
+				Description: "The events that start runs of this definition.",
+				Blocks: map[string]schema.Block{
+					"github": schema.SingleNestedBlock{
+						Description: "GitHub events.",
+						Blocks: map[string]schema.Block{
+							"push":         githubTriggerBlock("Starts a run when commits are pushed."),
+							"pull_request": githubTriggerBlock("Starts a run when a pull request is opened or updated."),
+						},
+					},
+					"cron": schema.ListNestedBlock{
+						Description:  "Starts a run on a schedule.",
+						NestedObject: schema.NestedBlockObject{Attributes: cronAttributes},
+					},
+				},
+			},
+			"concurrency_pool": schema.ListNestedBlock{
+				Description: "Limits how many runs in a pool may execute at once.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The pool's ID. Runs with the same ID share capacity.",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"capacity": schema.Int64Attribute{
+							Description: "How many runs in the pool may execute at once.",
+							Required:    true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"on_overflow": schema.StringAttribute{
+							Description: "What to do when the pool is full: queue or cancel-running.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("queue", "cancel-running"),
+							},
+						},
+					},
+				},
+			},
+			"base": schema.SingleNestedBlock{
+				Description: "The base layer that tasks run on.",
+				Validators: []validator.Object{
+					objectvalidator.AlsoRequires(
+						path.MatchRelative().AtName("os"),
+						path.MatchRelative().AtName("tag"),
+					),
+				},
+				Attributes: map[string]schema.Attribute{
+					"os": schema.StringAttribute{
+						Description: "The operating system, such as \"ubuntu 24.04\". Required when the block is present.",
+						Optional:    true,
+					},
+					"tag": schema.StringAttribute{
+						Description: "The version of the base layer, such as \"1.2\". Required when the block is present.",
+						Optional:    true,
+					},
+					"arch": schema.StringAttribute{
+						Description: "The CPU architecture: x86_64 or arm64.",
+						Optional:    true,
+						Validators: []validator.String{
+							stringvalidator.OneOf("x86_64", "arm64"),
+						},
+					},
+				},
+			},
+			"task": schema.ListNestedBlock{
+				Description: "The tasks in the run definition, in order.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							Description: "The task's key, unique within the run definition.",
+							Required:    true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+								stringvalidator.RegexMatches(
+									regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
+									"can only include alphanumeric characters, dashes, or underscores",
+								),
+							},
+						},
+						"call": schema.StringAttribute{
+							Description: "A package to call, such as \"git/clone 1.8.0\". Exactly one of call or run must be set.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("run")),
+							},
+						},
+						"run": schema.StringAttribute{
+							Description: "A script to run. Exactly one of call or run must be set.",
+							Optional:    true,
+						},
+						"use": schema.ListAttribute{
+							Description: "The keys of tasks whose output this task builds on.",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"after": schema.ListAttribute{
+							Description: "The keys of tasks that must finish before this task starts.",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"if": schema.StringAttribute{
+							Description: "An expression which must be true for the task to run.",
+							Optional:    true,
+						},
+						"with": schema.MapAttribute{
+							Description: "Parameters passed to the called package.",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"filter": schema.ListAttribute{
+							Description: "Paths the task reads. Other files are filtered out so that the task's cache key only depends on these.",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"env": schema.MapAttribute{
+							Description: "Environment variables set to literal values or expressions.",
+							Optional:    true,
+							ElementType: types.StringType,
+						},
+						"tool_cache": schema.StringAttribute{
+							Description: "The name of a tool cache the task reads and writes.",
+							Optional:    true,
+						},
+					},
+					Blocks: map[string]schema.Block{
+						"env_secret": schema.ListNestedBlock{
+							Description: "An environment variable set to a secret in a vault, typically from a mint_secret resource.",
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"name": schema.StringAttribute{
+										Description: "The name of the environment variable.",
+										Required:    true,
+										Validators:  envVarNameValidators,
+									},
+									"vault": schema.StringAttribute{
+										Description: "The name of the vault that holds the secret.",
+										Required:    true,
+										Validators:  vaultNameValidators,
+									},
+									"secret": schema.StringAttribute{
+										Description: "The name of the secret.",
+										Required:    true,
+										Validators:  vaultNameValidators,
+									},
+								},
+							},
+						},
+						"env_variable": schema.ListNestedBlock{
+							Description: "An environment variable set to a variable in a vault, typically from a mint_variable resource.",
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"name": schema.StringAttribute{
+										Description: "The name of the environment variable.",
+										Required:    true,
+										Validators:  envVarNameValidators,
+									},
+									"vault": schema.StringAttribute{
+										Description: "The name of the vault that holds the variable.",
+										Required:    true,
+										Validators:  vaultNameValidators,
+									},
+									"variable": schema.StringAttribute{
+										Description: "The name of the variable.",
+										Required:    true,
+										Validators:  vaultNameValidators,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *RunDefinitionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_run_definition", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var data RunDefinitionDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	definition := rundef.Definition{}
+
+	if data.ToolCache != nil {
+		definition.ToolCache = &rundef.ToolCache{Vault: data.ToolCache.Vault}
+	}
+
+	if data.On != nil {
+		definition.On = &rundef.Triggers{}
+		if data.On.GitHub != nil {
+			definition.On.GitHubPush = githubTriggers(data.On.GitHub.Push)
+			definition.On.GitHubPullRequest = githubTriggers(data.On.GitHub.PullRequest)
+		}
+		for _, trigger := range data.On.Cron {
+			definition.On.Cron = append(definition.On.Cron, rundef.CronTrigger{
+				Key:      trigger.Key,
+				Schedule: trigger.Schedule,
+				If:       trigger.If.ValueString(),
+				Init:     trigger.Init,
+				Target:   trigger.Target,
+			})
+		}
+	}
+
+	for _, pool := range data.ConcurrencyPools {
+		definition.ConcurrencyPools = append(definition.ConcurrencyPools, rundef.ConcurrencyPool{
+			ID:         pool.ID,
+			Capacity:   pool.Capacity,
+			OnOverflow: pool.OnOverflow.ValueString(),
+		})
+	}
+
+	if data.Base != nil {
+		definition.Base = &rundef.Base{
+			OS:   data.Base.OS,
+			Tag:  data.Base.Tag,
+			Arch: data.Base.Arch.ValueString(),
+		}
+	}
+
+	for i, task := range data.Tasks {
+		env := map[string]string{}
+		for name, value := range task.Env {
+			env[name] = value
+		}
+
+		addEnv := func(block string, j int, name string, value string) {
+			if _, ok := env[name]; ok {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("task").AtListIndex(i).AtName(block).AtListIndex(j).AtName("name"),
+					"Duplicate environment variable",
+					fmt.Sprintf("Task %q sets the environment variable %s more than once.", task.Key, name),
+				)
+			}
+			env[name] = value
+		}
+		for j, secret := range task.EnvSecret {
+			addEnv("env_secret", j, secret.Name, rundef.SecretExpression(secret.Vault, secret.Secret))
+		}
+		for j, variable := range task.EnvVariable {
+			addEnv("env_variable", j, variable.Name, rundef.VariableExpression(variable.Vault, variable.Variable))
+		}
+
+		definition.Tasks = append(definition.Tasks, rundef.Task{
+			Key:       task.Key,
+			Call:      task.Call.ValueString(),
+			Use:       task.Use,
+			After:     task.After,
+			If:        task.If.ValueString(),
+			Run:       task.Run.ValueString(),
+			With:      task.With,
+			Filter:    task.Filter,
+			Env:       env,
+			ToolCache: task.ToolCache.ValueString(),
+		})
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rendered, err := rundef.Render(definition)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to render run definition",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	for _, errorMessage := range rundef.Validate("run definition", rendered) {
+		resp.Diagnostics.AddError("Invalid run definition", errorMessage.String())
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.YAML = types.StringValue(string(rendered))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func githubTriggers(triggers []runDefinitionGitHubTriggerModel) []rundef.GitHubTrigger {
+	var result []rundef.GitHubTrigger
+	for _, trigger := range triggers {
+		result = append(result, rundef.GitHubTrigger{
+			If:     trigger.If.ValueString(),
+			Init:   trigger.Init,
+			Target: trigger.Target,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestRunDefinitionDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "mint_run_definition" "test" {
+  base {
+    os  = "ubuntu 24.04"
+    tag = "1.2"
+  }
+
+  task {
+    key  = "code"
+    call = "git/clone 1.8.0"
+    with = {
+      repository = "https://github.com/rwx-research/terraform-provider-mint.git"
+    }
+  }
+
+  task {
+    key = "test"
+    use = ["code"]
+    run = "go test ./..."
+
+    env_secret {
+      name   = "RWX_ACCESS_TOKEN"
+      vault  = "terraform_provider"
+      secret = "RWX_ACCESS_TOKEN"
+    }
+  }
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.mint_run_definition.test", tfjsonpath.New("yaml"), knownvalue.StringExact(`base:
+  os: ubuntu 24.04
+  tag: "1.2"
+tasks:
+  - key: code
+    call: git/clone 1.8.0
+    with:
+      repository: https://github.com/rwx-research/terraform-provider-mint.git
+  - key: test
+    use: [code]
+    run: go test ./...
+    env:
+      RWX_ACCESS_TOKEN: ${{ vaults.terraform_provider.secrets.RWX_ACCESS_TOKEN }}
+`)),
+				},
+			},
+			{
+				Config: providerConfig + `
+data "mint_run_definition" "test" {
+  task {
+    key = "test"
+    use = ["build"]
+    run = "go test ./..."
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Task "build" is not defined`),
+			},
+		},
+	})
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// checkRunDefinitionReferences adds a warning to the plan when it would destroy or replace a
+// secret or variable that is still referenced by a run definition in the provider's
+// run_definitions_directory, or remove a secret from one of its vaults. Runs using those definitions
+// would fail once it is gone, but the plan isn't blocked: the reference may be about to be removed
+// in the same change.
+func checkRunDefinitionReferences(ctx context.Context, directory string, kind rundef.ReferenceKind, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if directory == "" || req.State.Raw.IsNull() {
+		return
+	}
+
+	var vault, name types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("vault"), &vault)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("name"), &name)...)
+	vaults := stateVaults(ctx, req.State.Raw, req.State, vault, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	action := "destroy"
+	if !req.Plan.Raw.IsNull() {
+		var plannedVault, plannedName types.String
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("vault"), &plannedVault)...)
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("name"), &plannedName)...)
+		plannedVaults := stateVaults(ctx, resp.Plan.Raw, resp.Plan, plannedVault, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if plannedVault.Equal(vault) && plannedName.Equal(name) {
+			// Only vaults dropped from a set of vaults lose the secret.
+			action = "remove"
+			vaults = removedVaults(vaults, plannedVaults)
+		} else {
+			action = "replace"
+		}
+	}
+	if len(vaults) == 0 {
+		return
+	}
+
+	references, err := rundef.ScanDirectory(directory)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Unable to check run definitions for references",
+			fmt.Sprintf("The %s %q in vault %q could not be checked against the run definitions in %s: %s", kind, name.ValueString(), strings.Join(vaults, ", "), directory, err),
+		)
+		return
+	}
+
+	for _, vault := range vaults {
+		var locations []string
+		for _, reference := range references {
+			if reference.Kind == kind && reference.Vault == vault && reference.Name == name.ValueString() {
+				locations = append(locations, fmt.Sprintf("  %s:%d", reference.FileName, reference.Line))
+			}
+		}
+		if len(locations) == 0 {
+			continue
+		}
+
+		resp.Diagnostics.AddWarning(
+			fmt.Sprintf("The %s is still referenced by run definitions", kind),
+			fmt.Sprintf("This plan would %s the %s %q in vault %q, which is still referenced by:\n\n%s\n\n"+
+				"Runs using these definitions will fail until the references are removed.",
+				action, kind, name.ValueString(), vault, strings.Join(locations, "\n")),
+		)
+	}
+}
+
+// attributeGetter is satisfied by the state and plan exposed on framework requests and responses.
+type attributeGetter interface {
+	GetAttribute(ctx context.Context, path path.Path, target interface{}) diag.Diagnostics
+}
+
+// stateVaults returns the vaults holding a secret or variable: its vault, or the elements of its
+// vaults set for resources that can be written to several vaults.
+func stateVaults(ctx context.Context, raw tftypes.Value, data attributeGetter, vault types.String, diags *diag.Diagnostics) []string {
+	if !vault.IsNull() {
+		return []string{vault.ValueString()}
+	}
+	if object, ok := raw.Type().(tftypes.Object); !ok || object.AttributeTypes["vaults"] == nil {
+		return nil
+	}
+
+	var vaults types.Set
+	diags.Append(data.GetAttribute(ctx, path.Root("vaults"), &vaults)...)
+	if diags.HasError() || vaults.IsUnknown() {
+		return nil
+	}
+
+	names, d := setStrings(ctx, vaults)
+	diags.Append(d...)
+	return names
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the data source satisfies the framework interface.
+var _ datasource.DataSource = &RunDefinitionReferencesDataSource{}
+
+// vaultItemAttrTypes describes a secret, variable or OIDC token referenced from a vault.
+var vaultItemAttrTypes = map[string]attr.Type{
+	"vault": types.StringType,
+	"name":  types.StringType,
+}
+
+func NewRunDefinitionReferencesDataSource() datasource.DataSource {
+	return &RunDefinitionReferencesDataSource{}
+}
+
+// RunDefinitionReferencesDataSource lists what a directory of run definitions references. It only
+// reads local files, so it needs nothing from the provider's configuration.
+type RunDefinitionReferencesDataSource struct{}
+
+// RunDefinitionReferencesDataSourceModel describes the data source data model.
+type RunDefinitionReferencesDataSourceModel struct {
+	Directory  types.String `tfsdk:"directory"`
+	Vaults     types.Set    `tfsdk:"vaults"`
+	Secrets    types.Set    `tfsdk:"secrets"`
+	Variables  types.Set    `tfsdk:"variables"`
+	OIDCTokens types.Set    `tfsdk:"oidc_tokens"`
+}
+
+type vaultItemModel struct {
+	Vault types.String `tfsdk:"vault"`
+	Name  types.String `tfsdk:"name"`
+}
+
+func (d *RunDefinitionReferencesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_run_definition_references"
+}
+
+func (d *RunDefinitionReferencesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	vaultItemsAttribute := func(description string) schema.SetNestedAttribute {
+		return schema.SetNestedAttribute{
+			Description: description,
+			Computed:    true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: map[string]schema.Attribute{
+					"vault": schema.StringAttribute{
+						Description: "The name of the vault.",
+						Computed:    true,
+					},
+					"name": schema.StringAttribute{
+						Description: "The name within the vault.",
+						Computed:    true,
+					},
+				},
+			},
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Description: "Scans a directory of Mint run definitions and lists every vault, secret, variable and OIDC token they reference. Nothing is read from Mint.",
+		Attributes: map[string]schema.Attribute{
+			"directory": schema.StringAttribute{
+				Description: "The directory to scan, such as \"${path.module}/.mint\". Every .yml and .yaml file in it and its subdirectories is read.",
+				Required:    true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"vaults": schema.SetAttribute{
+				Description: "The names of every vault referenced, including the vaults of the secrets, variables and OIDC tokens below.",
+				Computed:    true,
+				ElementType: types.StringType,
+			},
+			"secrets":     vaultItemsAttribute("The secrets referenced, as ${{ vaults.<vault>.secrets.<name> }}."),
+			"variables":   vaultItemsAttribute("The variables referenced, as ${{ vaults.<vault>.vars.<name> }}."),
+			"oidc_tokens": vaultItemsAttribute("The OIDC tokens referenced, as ${{ vaults.<vault>.oidc.<name> }}."),
+		},
+	}
+}
+
+func (d *RunDefinitionReferencesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_run_definition_references", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var data RunDefinitionReferencesDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	references, err := rundef.ScanDirectory(data.Directory.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read run definitions",
+			err.Error(),
+		)
+		return
+	}
+
+	vaults := []string{}
+	items := map[rundef.ReferenceKind][]vaultItemModel{
+		rundef.ReferenceKindSecret:    {},
+		rundef.ReferenceKindVariable:  {},
+		rundef.ReferenceKindOIDCToken: {},
+	}
+	seen := map[rundef.Reference]bool{}
+	for _, reference := range references {
+		vault := rundef.Reference{Kind: rundef.ReferenceKindVault, Vault: reference.Vault}
+		if !seen[vault] {
+			seen[vault] = true
+			vaults = append(vaults, reference.Vault)
+		}
+
+		key := rundef.Reference{Kind: reference.Kind, Vault: reference.Vault, Name: reference.Name}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		items[reference.Kind] = append(items[reference.Kind], vaultItemModel{
+			Vault: types.StringValue(reference.Vault),
+			Name:  types.StringValue(reference.Name),
+		})
+	}
+
+	var diags diag.Diagnostics
+	vaultItemType := types.ObjectType{AttrTypes: vaultItemAttrTypes}
+
+	data.Vaults, diags = types.SetValueFrom(ctx, types.StringType, vaults)
+	resp.Diagnostics.Append(diags...)
+	data.Secrets, diags = types.SetValueFrom(ctx, vaultItemType, items[rundef.ReferenceKindSecret])
+	resp.Diagnostics.Append(diags...)
+	data.Variables, diags = types.SetValueFrom(ctx, vaultItemType, items[rundef.ReferenceKindVariable])
+	resp.Diagnostics.Append(diags...)
+	data.OIDCTokens, diags = types.SetValueFrom(ctx, vaultItemType, items[rundef.ReferenceKindOIDCToken])
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+)
+
+func TestRunDefinitionReferencesDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "mint_run_definition_references" "test" {
+  directory = "../../.mint"
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue("data.mint_run_definition_references.test", tfjsonpath.New("vaults"), knownvalue.SetExact([]knownvalue.Check{
+						knownvalue.StringExact("terraform_provider"),
+						knownvalue.StringExact("default"),
+					})),
+					statecheck.ExpectKnownValue("data.mint_run_definition_references.test", tfjsonpath.New("secrets"), knownvalue.SetExact([]knownvalue.Check{
+						knownvalue.ObjectExact(map[string]knownvalue.Check{"vault": knownvalue.StringExact("terraform_provider"), "name": knownvalue.StringExact("RWX_ACCESS_TOKEN")}),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{"vault": knownvalue.StringExact("terraform_provider"), "name": knownvalue.StringExact("GPG_PRIVATE_KEY")}),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{"vault": knownvalue.StringExact("terraform_provider"), "name": knownvalue.StringExact("GPG_PASSPHRASE")}),
+					})),
+					statecheck.ExpectKnownValue("data.mint_run_definition_references.test", tfjsonpath.New("variables"), knownvalue.SetSizeExact(0)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCheckRunDefinitionReferences(t *testing.T) {
+	ctx := context.Background()
+
+	var schemaResp resource.SchemaResponse
+	NewSecretResource().Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+
+	secret := func(vault string, name string) tftypes.Value {
+		attributes := map[string]tftypes.Value{}
+		for attribute, attributeType := range objectType.AttributeTypes {
+			attributes[attribute] = tftypes.NewValue(attributeType, nil)
+		}
+		attributes["vault"] = tftypes.NewValue(tftypes.String, vault)
+		attributes["name"] = tftypes.NewValue(tftypes.String, name)
+		attributes["secret_value"] = tftypes.NewValue(tftypes.String, "value")
+
+		return tftypes.NewValue(objectType, attributes)
+	}
+
+	replicated := func(name string, vaults ...string) tftypes.Value {
+		elements := make([]tftypes.Value, 0, len(vaults))
+		for _, vault := range vaults {
+			elements = append(elements, tftypes.NewValue(tftypes.String, vault))
+		}
+
+		attributes := map[string]tftypes.Value{}
+		for attribute, attributeType := range objectType.AttributeTypes {
+			attributes[attribute] = tftypes.NewValue(attributeType, nil)
+		}
+		attributes["vaults"] = tftypes.NewValue(tftypes.Set{ElementType: tftypes.String}, elements)
+		attributes["name"] = tftypes.NewValue(tftypes.String, name)
+		attributes["secret_value"] = tftypes.NewValue(tftypes.String, "value")
+
+		return tftypes.NewValue(objectType, attributes)
+	}
+
+	cases := []struct {
+		name      string
+		directory string
+		state     tftypes.Value
+		plan      tftypes.Value
+		warning   string
+	}{
+		{
+			name:      "destroying a referenced secret",
+			directory: "../../.mint",
+			state:     secret("terraform_provider", "GPG_PASSPHRASE"),
+			plan:      tftypes.NewValue(objectType, nil),
+			warning:   "This plan would destroy the secret \"GPG_PASSPHRASE\" in vault \"terraform_provider\", which is still referenced by:\n\n  ../../.mint/continuous_deployment.yml:130",
+		},
+		{
+			name:      "replacing a referenced secret",
+			directory: "../../.mint",
+			state:     secret("terraform_provider", "GPG_PASSPHRASE"),
+			plan:      secret("terraform_provider", "GPG_PASSPHRASE_V2"),
+			warning:   "This plan would replace the secret \"GPG_PASSPHRASE\"",
+		},
+		{
+			name:      "updating a referenced secret in place",
+			directory: "../../.mint",
+			state:     secret("terraform_provider", "GPG_PASSPHRASE"),
+			plan:      secret("terraform_provider", "GPG_PASSPHRASE"),
+		},
+		{
+			name:      "removing a referenced secret from one of its vaults",
+			directory: "../../.mint",
+			state:     replicated("GPG_PASSPHRASE", "other", "terraform_provider"),
+			plan:      replicated("GPG_PASSPHRASE", "other"),
+			warning:   "This plan would remove the secret \"GPG_PASSPHRASE\" in vault \"terraform_provider\"",
+		},
+		{
+			name:      "adding a vault to a referenced secret",
+			directory: "../../.mint",
+			state:     replicated("GPG_PASSPHRASE", "terraform_provider"),
+			plan:      replicated("GPG_PASSPHRASE", "other", "terraform_provider"),
+		},
+		{
+			name:      "destroying an unreferenced secret",
+			directory: "../../.mint",
+			state:     secret("terraform_provider", "UNUSED"),
+			plan:      tftypes.NewValue(objectType, nil),
+		},
+		{
+			name:  "no run definitions directory",
+			state: secret("terraform_provider", "GPG_PASSPHRASE"),
+			plan:  tftypes.NewValue(objectType, nil),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := resource.ModifyPlanRequest{
+				State: tfsdk.State{Schema: schemaResp.Schema, Raw: c.state},
+				Plan:  tfsdk.Plan{Schema: schemaResp.Schema, Raw: c.plan},
+			}
+			resp := resource.ModifyPlanResponse{
+				Plan: req.Plan,
+			}
+
+			checkRunDefinitionReferences(ctx, c.directory, rundef.ReferenceKindSecret, req, &resp)
+
+			if resp.Diagnostics.ErrorsCount() > 0 {
+				t.Fatalf("expected no errors, got %v", resp.Diagnostics)
+			}
+
+			warnings := resp.Diagnostics.Warnings()
+			if c.warning == "" {
+				if len(warnings) > 0 {
+					t.Errorf("expected no warnings, got %v", warnings)
+				}
+				return
+			}
+
+			if len(warnings) != 1 || !strings.HasPrefix(warnings[0].Detail(), c.warning) {
+				t.Errorf("expected a warning starting with %q, got %v", c.warning, warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/sops"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// secretSopsModel describes the secret_value_sops attribute of a secret.
+type secretSopsModel struct {
+	File types.String `tfsdk:"file"`
+	Key  types.String `tfsdk:"key"`
+}
+
+// encrypted reports whether a secret's value is decrypted inside the provider.
+func (m SecretResourceModel) encrypted() bool {
+	return m.SecretValueSops != nil || !m.SecretValueAge.IsNull()
+}
+
+// decryptSecretValue decrypts the value of a secret from secret_value_sops or secret_value_age with
+// the age identities sops would use. The plaintext is never stored in state. It returns false
+// without diagnostics when the encrypted input isn't known yet.
+func decryptSecretValue(model SecretResourceModel) ([]byte, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var attribute path.Path
+	var plaintext []byte
+
+	switch {
+	case model.SecretValueSops != nil:
+		attribute = path.Root("secret_value_sops")
+		if model.SecretValueSops.File.IsUnknown() || model.SecretValueSops.Key.IsUnknown() {
+			return nil, false, diags
+		}
+
+		file := model.SecretValueSops.File.ValueString()
+		source, err := os.ReadFile(file)
+		if err != nil {
+			diags.AddAttributeError(attribute, "Unable to read SOPS file", err.Error())
+			return nil, false, diags
+		}
+
+		identities, err := sops.Identities()
+		if err != nil {
+			diags.AddAttributeError(attribute, "Unable to load age identities", err.Error())
+			return nil, false, diags
+		}
+
+		plaintext, err = sops.Decrypt(source, model.SecretValueSops.Key.ValueString(), identities)
+		if err != nil {
+			diags.AddAttributeError(attribute, "Unable to decrypt secret value", fmt.Sprintf("Unable to decrypt %s: %s", file, err))
+			return nil, false, diags
+		}
+	case !model.SecretValueAge.IsNull():
+		attribute = path.Root("secret_value_age")
+		if model.SecretValueAge.IsUnknown() {
+			return nil, false, diags
+		}
+
+		identities, err := sops.Identities()
+		if err != nil {
+			diags.AddAttributeError(attribute, "Unable to load age identities", err.Error())
+			return nil, false, diags
+		}
+
+		plaintext, err = sops.DecryptArmored(model.SecretValueAge.ValueString(), identities)
+		if err != nil {
+			diags.AddAttributeError(attribute, "Unable to decrypt secret value", err.Error())
+			return nil, false, diags
+		}
+	default:
+		return nil, false, diags
+	}
+
+	if len(plaintext) == 0 || len(plaintext) > maxSecretValueBytes {
+		diags.AddAttributeError(
+			attribute,
+			"Invalid Secret Size",
+			fmt.Sprintf("The decrypted secret value must be between 1 and %d bytes, got: %d bytes", maxSecretValueBytes, len(plaintext)),
+		)
+		return nil, false, diags
+	}
+
+	return plaintext, true, diags
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/sops"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestDecryptSecretValue(t *testing.T) {
+	t.Setenv(sops.AgeKeyFileEnv, "../sops/testdata/keys.txt")
+
+	armored, err := os.ReadFile("../sops/testdata/token.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		model    SecretResourceModel
+		expected string
+		known    bool
+		err      string
+	}{
+		{
+			name:     "sops",
+			model:    SecretResourceModel{SecretValueSops: &secretSopsModel{File: types.StringValue("../sops/testdata/secrets.enc.yaml"), Key: types.StringValue("registry.password")}},
+			expected: "hunter2",
+			known:    true,
+		},
+		{
+			name:     "age",
+			model:    SecretResourceModel{SecretValueAge: types.StringValue(string(armored))},
+			expected: "binary\x00value",
+			known:    true,
+		},
+		{
+			name:  "unknown file",
+			model: SecretResourceModel{SecretValueSops: &secretSopsModel{File: types.StringUnknown(), Key: types.StringValue("api_token")}},
+		},
+		{
+			name:  "missing file",
+			model: SecretResourceModel{SecretValueSops: &secretSopsModel{File: types.StringValue("missing.enc.yaml"), Key: types.StringValue("api_token")}},
+			err:   "Unable to read SOPS file",
+		},
+		{
+			name:  "missing key",
+			model: SecretResourceModel{SecretValueSops: &secretSopsModel{File: types.StringValue("../sops/testdata/secrets.enc.yaml"), Key: types.StringValue("missing")}},
+			err:   "Unable to decrypt secret value",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			plaintext, known, diags := decryptSecretValue(c.model)
+			if c.err != "" {
+				if !diags.HasError() || !strings.Contains(diags[0].Summary(), c.err) {
+					t.Fatalf("expected error %q, got %v", c.err, diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if known != c.known || string(plaintext) != c.expected {
+				t.Errorf("expected %q (known %t), got %q (known %t)", c.expected, c.known, plaintext, known)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// checkSecretExpiry warns when a secret's expires_at is within warningDays of now, and fails the plan
+// once it has passed, so that expired third-party tokens are noticed before runs start failing.
+func checkSecretExpiry(now time.Time, name string, expiresAt types.String, warningDays int64) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	expiry, ok := parseExpiresAt(expiresAt)
+	if !ok {
+		return diags
+	}
+
+	if !now.Before(expiry) {
+		diags.AddAttributeError(
+			path.Root("expires_at"),
+			"Secret has expired",
+			fmt.Sprintf("Secret %q expired at %s. Rotate the secret and update expires_at.", name, expiry.Format(time.RFC3339)),
+		)
+		return diags
+	}
+
+	remaining := expiry.Sub(now)
+	if remaining <= time.Duration(warningDays)*24*time.Hour {
+		days := int(math.Ceil(remaining.Hours() / 24))
+		diags.AddAttributeWarning(
+			path.Root("expires_at"),
+			"Secret expires soon",
+			fmt.Sprintf("Secret %q expires at %s, in %d day(s). Rotate the secret and update expires_at.", name, expiry.Format(time.RFC3339), days),
+		)
+	}
+
+	return diags
+}
+
+// parseExpiresAt returns the time in an expires_at attribute, if it is set and valid.
+func parseExpiresAt(expiresAt types.String) (time.Time, bool) {
+	if expiresAt.IsNull() || expiresAt.IsUnknown() {
+		return time.Time{}, false
+	}
+
+	expiry, err := time.Parse(time.RFC3339, expiresAt.ValueString())
+	return expiry, err == nil
+}
+
+// expiresAtFromAPI returns the expires_at to keep in state for the expiry Mint reported. A value
+// that refers to the same instant is left as configured, so that a different but equivalent format
+// isn't shown as a change.
+func expiresAtFromAPI(current types.String, expiry *time.Time) types.String {
+	if expiry == nil {
+		return types.StringNull()
+	}
+
+	if configured, ok := parseExpiresAt(current); ok && configured.Equal(*expiry) {
+		return current
+	}
+
+	return types.StringValue(expiry.UTC().Format(time.RFC3339))
+}
+
+// expiresAtValue returns the expiry to send to Mint for an expires_at attribute.
+func expiresAtValue(expiresAt types.String) *time.Time {
+	expiry, ok := parseExpiresAt(expiresAt)
+	if !ok {
+		return nil
+	}
+
+	return &expiry
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCheckSecretExpiry(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		expiresAt types.String
+		severity  diag.Severity
+		summary   string
+	}{
+		{name: "not set", expiresAt: types.StringNull()},
+		{name: "unknown", expiresAt: types.StringUnknown()},
+		{name: "far off", expiresAt: types.StringValue("2030-06-01T00:00:00Z")},
+		{name: "within the window", expiresAt: types.StringValue("2030-01-10T00:00:00Z"), severity: diag.SeverityWarning, summary: "Secret expires soon"},
+		{name: "expired", expiresAt: types.StringValue("2030-01-01T11:59:59Z"), severity: diag.SeverityError, summary: "Secret has expired"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			diags := checkSecretExpiry(now, "API_KEY", c.expiresAt, 30)
+			if c.summary == "" {
+				if len(diags) != 0 {
+					t.Fatalf("expected no diagnostics, got %v", diags)
+				}
+				return
+			}
+
+			if len(diags) != 1 || diags[0].Severity() != c.severity || diags[0].Summary() != c.summary {
+				t.Fatalf("expected a single %q diagnostic, got %v", c.summary, diags)
+			}
+		})
+	}
+}
+
+func TestExpiresAtFromAPI(t *testing.T) {
+	expiry := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// An equivalent timestamp in another offset is kept as configured.
+	configured := types.StringValue("2030-01-01T13:00:00+01:00")
+	if got := expiresAtFromAPI(configured, &expiry); !got.Equal(configured) {
+		t.Errorf("expected %s, got %s", configured, got)
+	}
+
+	changed := time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+	if got := expiresAtFromAPI(configured, &changed); got.ValueString() != "2030-02-01T00:00:00Z" {
+		t.Errorf("expected the expiry from Mint, got %s", got)
+	}
+
+	if got := expiresAtFromAPI(configured, nil); !got.IsNull() {
+		t.Errorf("expected null, got %s", got)
+	}
+}
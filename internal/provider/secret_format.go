@@ -0,0 +1,261 @@
+package provider
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// secretFormats are the formats a secret's value can be checked against.
+var secretFormats = []string{"pem", "ssh_private_key", "json", "jwt", "dotenv"}
+
+var formatFactsAttrTypes = map[string]attr.Type{
+	"key_type":  types.StringType,
+	"not_after": types.StringType,
+}
+
+// dotenvLine matches an assignment in a dotenv file, with an optional export prefix.
+var dotenvLine = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// formatFacts are what can be learned about a secret's value from its format without revealing it.
+type formatFacts struct {
+	// KeyType describes the key, such as "RSA 4096" or "ssh-ed25519", or a JWT's signing algorithm.
+	KeyType string
+
+	// NotAfter is when the earliest certificate in a PEM bundle expires, or a JWT's exp claim.
+	NotAfter *time.Time
+}
+
+// object returns the facts as the value of the format_facts attribute.
+func (f formatFacts) object() types.Object {
+	keyType, notAfter := types.StringNull(), types.StringNull()
+	if f.KeyType != "" {
+		keyType = types.StringValue(f.KeyType)
+	}
+	if f.NotAfter != nil {
+		notAfter = types.StringValue(f.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return types.ObjectValueMust(formatFactsAttrTypes, map[string]attr.Value{
+		"key_type":  keyType,
+		"not_after": notAfter,
+	})
+}
+
+// checkSecretFormat parses a secret's value in the given format. Errors describe where the value is
+// malformed, such as a line number or byte offset, but never include any of the value itself.
+func checkSecretFormat(format string, value []byte) (formatFacts, error) {
+	switch format {
+	case "pem":
+		return checkPEM(value)
+	case "ssh_private_key":
+		return checkSSHPrivateKey(value)
+	case "json":
+		return formatFacts{}, checkJSON(value)
+	case "jwt":
+		return checkJWT(value)
+	case "dotenv":
+		return formatFacts{}, checkDotenv(value)
+	default:
+		return formatFacts{}, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func checkPEM(value []byte) (formatFacts, error) {
+	var facts formatFacts
+
+	rest := bytes.TrimSpace(value)
+	if len(rest) == 0 {
+		return facts, errors.New("no PEM blocks found")
+	}
+
+	for i := 1; len(rest) > 0; i++ {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return facts, fmt.Errorf("PEM block %d is truncated or corrupt", i)
+		}
+		rest = bytes.TrimSpace(rest)
+
+		switch block.Type {
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return facts, fmt.Errorf("PEM block %d (CERTIFICATE) is not a valid certificate", i)
+			}
+			if facts.NotAfter == nil || certificate.NotAfter.Before(*facts.NotAfter) {
+				facts.NotAfter = &certificate.NotAfter
+			}
+			if facts.KeyType == "" {
+				facts.KeyType = describePublicKey(certificate.PublicKey)
+			}
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY", "OPENSSH PRIVATE KEY":
+			key, err := ssh.ParseRawPrivateKey(pem.EncodeToMemory(block))
+			if err != nil {
+				return facts, fmt.Errorf("PEM block %d (%s) is not a valid private key", i, block.Type)
+			}
+			if signer, ok := key.(crypto.Signer); ok {
+				facts.KeyType = describePublicKey(signer.Public())
+			}
+		case "ENCRYPTED PRIVATE KEY":
+			// Encrypted keys can't be checked without their passphrase.
+		default:
+			if block.Type == "" {
+				return facts, fmt.Errorf("PEM block %d has no type", i)
+			}
+		}
+	}
+
+	return facts, nil
+}
+
+func checkSSHPrivateKey(value []byte) (formatFacts, error) {
+	key, err := ssh.ParseRawPrivateKey(value)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) && missing.PublicKey != nil {
+			return formatFacts{KeyType: missing.PublicKey.Type()}, nil
+		}
+
+		return formatFacts{}, errors.New("not a valid unencrypted or passphrase-protected SSH private key")
+	}
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return formatFacts{}, errors.New("unsupported SSH private key type")
+	}
+
+	return formatFacts{KeyType: signer.PublicKey().Type()}, nil
+}
+
+func checkJSON(value []byte) error {
+	var decoded any
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return fmt.Errorf("invalid JSON at byte offset %d", syntax.Offset)
+		}
+
+		return errors.New("invalid JSON")
+	}
+
+	return nil
+}
+
+func checkJWT(value []byte) (formatFacts, error) {
+	parts := strings.Split(strings.TrimSpace(string(value)), ".")
+	if len(parts) != 3 {
+		return formatFacts{}, fmt.Errorf("a JWT has 3 dot-separated parts, got %d", len(parts))
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	for i, decoded := range []any{&header, &claims} {
+		name := []string{"header", "payload"}[i]
+
+		segment, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			return formatFacts{}, fmt.Errorf("the JWT %s is not base64url", name)
+		}
+		if err := json.Unmarshal(segment, decoded); err != nil {
+			return formatFacts{}, fmt.Errorf("the JWT %s is not a JSON object", name)
+		}
+	}
+	if header.Alg == "" {
+		return formatFacts{}, errors.New("the JWT header has no alg")
+	}
+	if _, err := base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return formatFacts{}, errors.New("the JWT signature is not base64url")
+	}
+
+	facts := formatFacts{KeyType: header.Alg}
+	if claims.Exp != nil {
+		exp, err := claims.Exp.Int64()
+		if err != nil {
+			return formatFacts{}, errors.New("the JWT exp claim is not a whole number of seconds")
+		}
+		notAfter := time.Unix(exp, 0)
+		facts.NotAfter = &notAfter
+	}
+
+	return facts, nil
+}
+
+func checkDotenv(value []byte) error {
+	lines := strings.Split(strings.ReplaceAll(string(value), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		matches := dotenvLine.FindStringSubmatch(line)
+		if matches == nil {
+			return fmt.Errorf("line %d is not a KEY=VALUE assignment", i+1)
+		}
+
+		// Quoted values may span lines until the closing quote.
+		assigned := matches[2]
+		if quote := assigned[:min(1, len(assigned))]; quote == `"` || quote == "'" {
+			start := i
+			for !closesQuote(assigned[1:], quote) {
+				i++
+				if i == len(lines) {
+					return fmt.Errorf("line %d has an unterminated quoted value", start+1)
+				}
+				assigned += "\n" + lines[i]
+			}
+		}
+	}
+
+	return nil
+}
+
+// closesQuote reports whether a quoted value, after its opening quote, contains the closing quote.
+// Double-quoted values may escape quotes with a backslash.
+func closesQuote(value string, quote string) bool {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case quote == `"` && value[i] == '\\':
+			i++
+		case value[i:i+1] == quote:
+			return true
+		}
+	}
+
+	return false
+}
+
+// describePublicKey returns a short description of a key's type and size, such as "RSA 4096".
+func describePublicKey(key any) string {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "ED25519"
+	default:
+		return fmt.Sprintf("%T", key)
+	}
+}
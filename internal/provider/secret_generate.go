@@ -0,0 +1,123 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const defaultGeneratedLength = 32
+
+const (
+	upperCharacters   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	lowerCharacters   = "abcdefghijklmnopqrstuvwxyz"
+	numericCharacters = "0123456789"
+	specialCharacters = "!@#$%&*()-_=+[]{}<>:?"
+)
+
+// secretGenerateModel describes the generate block of a secret.
+type secretGenerateModel struct {
+	Length   types.Int64  `tfsdk:"length"`
+	Encoding types.String `tfsdk:"encoding"`
+	Upper    types.Bool   `tfsdk:"upper"`
+	Lower    types.Bool   `tfsdk:"lower"`
+	Numeric  types.Bool   `tfsdk:"numeric"`
+	Special  types.Bool   `tfsdk:"special"`
+}
+
+// characterClassesSet reports whether any of the character classes, which only apply to the alnum
+// encoding, were configured.
+func (g secretGenerateModel) characterClassesSet() bool {
+	return !g.Upper.IsNull() || !g.Lower.IsNull() || !g.Numeric.IsNull() || !g.Special.IsNull()
+}
+
+// characters returns the characters an alnum value is drawn from.
+func (g secretGenerateModel) characters() string {
+	var characters strings.Builder
+
+	if g.Upper.IsNull() || g.Upper.ValueBool() {
+		characters.WriteString(upperCharacters)
+	}
+	if g.Lower.IsNull() || g.Lower.ValueBool() {
+		characters.WriteString(lowerCharacters)
+	}
+	if g.Numeric.IsNull() || g.Numeric.ValueBool() {
+		characters.WriteString(numericCharacters)
+	}
+	if g.Special.ValueBool() {
+		characters.WriteString(specialCharacters)
+	}
+
+	return characters.String()
+}
+
+// generate returns a new random value. For the alnum encoding, length is the number of
+// characters; for hex and base64 it is the number of random bytes before encoding.
+func (g secretGenerateModel) generate() (string, error) {
+	length := int(g.Length.ValueInt64())
+	if g.Length.IsNull() {
+		length = defaultGeneratedLength
+	}
+
+	switch g.Encoding.ValueString() {
+	case "hex", "base64":
+		bytes := make([]byte, length)
+		if _, err := rand.Read(bytes); err != nil {
+			return "", err
+		}
+
+		if g.Encoding.ValueString() == "hex" {
+			return hex.EncodeToString(bytes), nil
+		}
+		return base64.StdEncoding.EncodeToString(bytes), nil
+	case "", "alnum":
+		characters := g.characters()
+		if characters == "" {
+			return "", fmt.Errorf("no character classes are enabled")
+		}
+
+		max := big.NewInt(int64(len(characters)))
+		value := make([]byte, length)
+		for i := range value {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", err
+			}
+			value[i] = characters[n.Int64()]
+		}
+
+		return string(value), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q", g.Encoding.ValueString())
+	}
+}
+
+// generatedWriteFingerprint identifies a write of a generated value by the settings it was
+// generated from rather than by the value, which differs on every attempt. Retrying an update whose
+// response was lost therefore reuses its idempotency key, and Mint keeps the value from the first
+// attempt.
+func generatedWriteFingerprint(ctx context.Context, g secretGenerateModel, keepers types.Map) (string, diag.Diagnostics) {
+	var elements map[string]string
+	diags := keepers.ElementsAs(ctx, &elements, false)
+
+	keys := make([]string, 0, len(elements))
+	for key := range elements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := []string{"generate", g.Length.String(), g.Encoding.String(), g.Upper.String(), g.Lower.String(), g.Numeric.String(), g.Special.String()}
+	for _, key := range keys {
+		parts = append(parts, key, elements[key])
+	}
+
+	return fingerprintWrite(parts...), diags
+}
@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestSecretGenerate(t *testing.T) {
+	cases := []struct {
+		name     string
+		generate secretGenerateModel
+		check    func(t *testing.T, value string)
+	}{
+		{
+			name:     "alnum by default",
+			generate: secretGenerateModel{Length: types.Int64Value(40)},
+			check: func(t *testing.T, value string) {
+				if len(value) != 40 {
+					t.Errorf("expected 40 characters, got %d", len(value))
+				}
+				if strings.Trim(value, upperCharacters+lowerCharacters+numericCharacters) != "" {
+					t.Errorf("expected only alphanumeric characters, got %q", value)
+				}
+			},
+		},
+		{
+			name:     "digits only",
+			generate: secretGenerateModel{Length: types.Int64Value(6), Upper: types.BoolValue(false), Lower: types.BoolValue(false)},
+			check: func(t *testing.T, value string) {
+				if len(value) != 6 || strings.Trim(value, numericCharacters) != "" {
+					t.Errorf("expected 6 digits, got %q", value)
+				}
+			},
+		},
+		{
+			name:     "hex",
+			generate: secretGenerateModel{Length: types.Int64Value(32), Encoding: types.StringValue("hex")},
+			check: func(t *testing.T, value string) {
+				if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != 32 {
+					t.Errorf("expected 32 hex-encoded bytes, got %q", value)
+				}
+			},
+		},
+		{
+			name:     "base64",
+			generate: secretGenerateModel{Length: types.Int64Value(64), Encoding: types.StringValue("base64")},
+			check: func(t *testing.T, value string) {
+				if decoded, err := base64.StdEncoding.DecodeString(value); err != nil || len(decoded) != 64 {
+					t.Errorf("expected 64 base64-encoded bytes, got %q", value)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value, err := c.generate.generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.check(t, value)
+
+			again, err := c.generate.generate()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again == value {
+				t.Errorf("expected a different value each time, got %q twice", value)
+			}
+		})
+	}
+}
+
+func TestSecretGenerateApply(t *testing.T) {
+	p := newTestProvider(t)
+	generateType := p.schemas["mint_secret"].ValueType().(tftypes.Object).AttributeTypes["generate"]
+	configWith := func(keeper string) tftypes.Value {
+		return p.config("mint_secret", map[string]tftypes.Value{
+			"vault":    tfString("default"),
+			"name":     tfString("API_KEY"),
+			"generate": objectWith(generateType, nil),
+			"keepers":  tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, map[string]tftypes.Value{"rotation": tfString(keeper)}),
+		})
+	}
+
+	state, diags := p.apply("mint_secret", testState{}, configWith("1"))
+	p.requireNoErrors(diags)
+
+	value := p.mint.secrets["default/API_KEY"].SecretValue
+	if len(value) != defaultGeneratedLength {
+		t.Fatalf("expected a generated value of %d characters, got %q", defaultGeneratedLength, value)
+	}
+
+	_, diags = p.apply("mint_secret", state, configWith("2"))
+	p.requireNoErrors(diags)
+
+	if p.mint.secrets["default/API_KEY"].SecretValue == value {
+		t.Error("expected a new value to be generated when the keepers change")
+	}
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// privateMovedVariableKey holds the variable a secret was moved from until the secret has been
+// written and the variable removed.
+const privateMovedVariableKey = "moved_variable"
+
+// movedVariable identifies the Mint variable a secret's state was moved from.
+type movedVariable struct {
+	Vault string `json:"vault"`
+	Name  string `json:"name"`
+}
+
+// MoveState accepts the state of a mint_variable, so that a moved block can promote a variable to a
+// secret. Its value, value_json or sensitive_value becomes the secret's secret_value. The state is
+// moved as is, and the next apply writes the secret before deleting the variable, so that there is
+// never a moment when neither exists.
+func (r *SecretResource) MoveState(ctx context.Context) []resource.StateMover {
+	return []resource.StateMover{
+		{StateMover: moveVariableState},
+	}
+}
+
+func moveVariableState(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	// Leave other sources to the framework, which reports them as unsupported.
+	if !strings.HasSuffix(req.SourceProviderAddress, "/mint") || req.SourceTypeName != "mint_variable" {
+		return
+	}
+
+	// The raw state is decoded directly rather than with the variable's schema, so that state
+	// written by any version of mint_variable can be moved.
+	var variable struct {
+		Vault          string  `json:"vault"`
+		Name           string  `json:"name"`
+		Value          string  `json:"value"`
+		ValueJSON      *string `json:"value_json"`
+		SensitiveValue *string `json:"sensitive_value"`
+	}
+	if req.SourceRawState == nil || json.Unmarshal(req.SourceRawState.JSON, &variable) != nil || variable.Vault == "" || variable.Name == "" {
+		resp.Diagnostics.AddError(
+			"Unable to move mint_variable state",
+			"The mint_variable state has no vault and name. Refresh it before moving it to a mint_secret.",
+		)
+		return
+	}
+
+	switch {
+	case variable.SensitiveValue != nil:
+		variable.Value = *variable.SensitiveValue
+	case variable.ValueJSON != nil:
+		variable.Value = *variable.ValueJSON
+	}
+
+	state := SecretResourceModel{
+		Vault:             types.StringValue(variable.Vault),
+		Vaults:            types.SetNull(types.StringType),
+		Name:              types.StringValue(variable.Name),
+		SecretValue:       types.StringValue(variable.Value),
+		SecretValueBase64: types.StringNull(),
+		SecretValueAge:    types.StringNull(),
+		SecretValueLength: types.Int64Null(),
+		SecretValueSHA256: types.StringNull(),
+		Description:       types.StringNull(),
+		ExpiresAt:         types.StringNull(),
+		Format:            types.StringNull(),
+		FormatFacts:       types.ObjectNull(formatFactsAttrTypes),
+		Keepers:           types.MapNull(types.StringType),
+		ID:                resourceID([]string{variable.Vault}, variable.Name),
+		CreatedAt:         types.StringNull(),
+		UpdatedAt:         types.StringNull(),
+		UpdatedBy:         types.StringNull(),
+	}
+
+	encoded, err := json.Marshal(movedVariable{Vault: variable.Vault, Name: variable.Name})
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to record moved variable", "Unexpected error: "+err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(resp.TargetPrivate.SetKey(ctx, privateMovedVariableKey, encoded)...)
+	resp.Diagnostics.Append(resp.TargetState.Set(ctx, state)...)
+}
+
+// getMovedVariable returns the variable a secret was moved from, if it still has to be removed.
+func getMovedVariable(ctx context.Context, private privateGetter) (movedVariable, bool) {
+	var moved movedVariable
+
+	value, diags := private.GetKey(ctx, privateMovedVariableKey)
+	if diags.HasError() || value == nil || json.Unmarshal(value, &moved) != nil {
+		return movedVariable{}, false
+	}
+
+	return moved, moved.Name != ""
+}
+
+// checkMovedVariable plans the write of a secret moved from a variable, even if its value is
+// unchanged or the state was not refreshed, so that the variable is removed. It fails a plan that
+// would not write the secret in place of the variable: without a value there is nothing to write,
+// and a replacement would be created under a different name or vault without the variable ever
+// being removed.
+func checkMovedVariable(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	moved, ok := getMovedVariable(ctx, req.Private)
+	if !ok {
+		return
+	}
+
+	var plan SecretResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.managesValue() {
+		resp.Diagnostics.AddError(
+			"Moved variable must have a secret value",
+			fmt.Sprintf("This secret was moved from the variable %q in vault %q, so it must set secret_value, secret_value_base64, "+
+				"secret_value_sops, secret_value_age or generate to write the secret that replaces it.", moved.Name, moved.Vault),
+		)
+		return
+	}
+
+	for _, attribute := range []string{"created_at", "updated_at", "updated_by"} {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root(attribute), types.StringUnknown())...)
+	}
+
+	vault, name := plan.Vault, plan.Name
+	if vault.IsUnknown() || name.IsUnknown() {
+		return
+	}
+
+	if vault.ValueString() != moved.Vault || name.ValueString() != moved.Name {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Moved variable must keep its vault and name",
+			fmt.Sprintf("This secret was moved from the variable %q in vault %q. Keep its vault and name until it has been applied, "+
+				"then rename it in a later change.", moved.Name, moved.Vault),
+		)
+	}
+}
+
+// removeMovedVariable deletes the variable a secret was moved from, once the secret holds its value.
+func (r *SecretResource) removeMovedVariable(ctx context.Context, moved movedVariable, next privateSetter, diags *diag.Diagnostics) {
+	if err := r.client.DeleteVariableInVault(ctx, moved.Vault, api.Variable{Name: moved.Name}); err != nil && !errors.Is(err, api.ErrNotFound) {
+		diags.AddError(
+			"Error deleting moved variable in Mint",
+			fmt.Sprintf("The secret was written, but the variable %q in vault %q it was moved from could not be deleted: %s", moved.Name, moved.Vault, err),
+		)
+		return
+	}
+
+	diags.Append(next.SetKey(ctx, privateMovedVariableKey, nil)...)
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	tfresource "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestSecretResourceMovedFromVariable(t *testing.T) {
+	tfresource.Test(t, tfresource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []tfresource.TestStep{
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-promoted"
+  value = "foo"
+}
+`,
+			},
+			// Moving writes the secret and removes the variable
+			{
+				Config: providerConfig + `
+moved {
+  from = mint_variable.test
+  to   = mint_secret.test
+}
+
+resource "mint_secret" "test" {
+  vault        = "terraform_provider_testing"
+  name         = "test-promoted"
+  secret_value = "foo"
+}
+`,
+				Check: tfresource.ComposeAggregateTestCheckFunc(
+					tfresource.TestCheckResourceAttr("mint_secret.test", "secret_value", "foo"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestSecretMovedFromVariableIsWrittenWithoutRefresh(t *testing.T) {
+	p := newTestProvider(t)
+	p.mint.variables["default/HOSTNAME"] = api.Variable{Name: "HOSTNAME", Value: "internal.example.com", Version: 1}
+
+	// Applied as with -refresh=false, the unchanged value is still written and the variable removed.
+	state := p.move("mint_secret", "mint_variable", `{"vault":"default","name":"HOSTNAME","value":"internal.example.com"}`)
+	_, diags := p.apply("mint_secret", state, p.config("mint_secret", map[string]tftypes.Value{
+		"vault":        tfString("default"),
+		"name":         tfString("HOSTNAME"),
+		"secret_value": tfString("internal.example.com"),
+	}))
+	p.requireNoErrors(diags)
+
+	if p.mint.secrets["default/HOSTNAME"].SecretValue != "internal.example.com" {
+		t.Error("expected the secret to be written to Mint")
+	}
+	if _, ok := p.mint.variables["default/HOSTNAME"]; ok {
+		t.Error("expected the variable to be removed")
+	}
+}
+
+func TestSecretMovedFromVariableDoesNotOverwriteSecret(t *testing.T) {
+	p := newTestProvider(t)
+	p.mint.ignoreCreateOnly = true
+	p.mint.variables["default/HOSTNAME"] = api.Variable{Name: "HOSTNAME", Value: "internal.example.com", Version: 1}
+	p.mint.secrets["default/HOSTNAME"] = api.Secret{Name: "HOSTNAME", SecretValue: "existing", Version: 1}
+
+	state := p.move("mint_secret", "mint_variable", `{"vault":"default","name":"HOSTNAME","value":"internal.example.com"}`)
+	_, diags := p.apply("mint_secret", state, p.config("mint_secret", map[string]tftypes.Value{
+		"vault":        tfString("default"),
+		"name":         tfString("HOSTNAME"),
+		"secret_value": tfString("internal.example.com"),
+	}))
+
+	if !strings.Contains(diagnosticsString(diags), "Secret already exists in Vault") {
+		t.Errorf("expected the write to be refused, got: %s", diagnosticsString(diags))
+	}
+	if p.mint.secrets["default/HOSTNAME"].SecretValue != "existing" {
+		t.Error("expected the existing secret not to be overwritten")
+	}
+	if _, ok := p.mint.variables["default/HOSTNAME"]; !ok {
+		t.Error("expected the variable to be kept")
+	}
+}
+
+func TestMoveVariableState(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schemaResp resource.SchemaResponse
+	NewSecretResource().Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	move := func(sourceTypeName string, state string) *tfprotov6.MoveResourceStateResponse {
+		resp, err := server.MoveResourceState(ctx, &tfprotov6.MoveResourceStateRequest{
+			SourceProviderAddress: "registry.terraform.io/rwx-research/mint",
+			SourceTypeName:        sourceTypeName,
+			SourceState:           &tfprotov6.RawState{JSON: []byte(state)},
+			TargetTypeName:        "mint_secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resp
+	}
+
+	t.Run("from mint_variable", func(t *testing.T) {
+		resp := move("mint_variable", `{"vault":"default","name":"HOSTNAME","value":"internal.example.com"}`)
+		if len(resp.Diagnostics) > 0 {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		state, err := resp.TargetState.Unmarshal(objectType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var attributes map[string]tftypes.Value
+		if err := state.As(&attributes); err != nil {
+			t.Fatal(err)
+		}
+		for attribute, expected := range map[string]string{
+			"vault":        "default",
+			"name":         "HOSTNAME",
+			"secret_value": "internal.example.com",
+		} {
+			var actual string
+			if err := attributes[attribute].As(&actual); err != nil || actual != expected {
+				t.Errorf("expected %s to be %q, got %q", attribute, expected, actual)
+			}
+		}
+
+		// The variable is only removed once the secret has been written.
+		if !strings.Contains(string(resp.TargetPrivate), privateMovedVariableKey) {
+			t.Errorf("expected the moved variable to be recorded in private state, got %s", resp.TargetPrivate)
+		}
+	})
+
+	t.Run("from mint_variable with value_json", func(t *testing.T) {
+		resp := move("mint_variable", `{"vault":"default","name":"CONFIG","value":null,"value_json":"{\"a\":1}"}`)
+		if len(resp.Diagnostics) > 0 {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		state, err := resp.TargetState.Unmarshal(objectType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var attributes map[string]tftypes.Value
+		var value string
+		if err := state.As(&attributes); err != nil {
+			t.Fatal(err)
+		}
+		if err := attributes["secret_value"].As(&value); err != nil || value != `{"a":1}` {
+			t.Errorf("expected secret_value to be the JSON document, got %q", value)
+		}
+	})
+
+	t.Run("from mint_variable with sensitive_value", func(t *testing.T) {
+		resp := move("mint_variable", `{"vault":"default","name":"ACCOUNT_ID","value":null,"value_json":null,"sensitive_value":"123456789012"}`)
+		if len(resp.Diagnostics) > 0 {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+
+		state, err := resp.TargetState.Unmarshal(objectType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var attributes map[string]tftypes.Value
+		var value string
+		if err := state.As(&attributes); err != nil {
+			t.Fatal(err)
+		}
+		if err := attributes["secret_value"].As(&value); err != nil || value != "123456789012" {
+			t.Errorf("expected secret_value to be the sensitive value, got %q", value)
+		}
+	})
+
+	t.Run("from another resource type", func(t *testing.T) {
+		resp := move("mint_access_token", `{"name":"token"}`)
+		if len(resp.Diagnostics) == 0 {
+			t.Error("expected the move to be unsupported")
+		}
+	})
+}
@@ -2,14 +2,29 @@ package provider
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
-	"strconv"
+	"sort"
+	"time"
 
 	"github.com/rwx-research/terraform-provider-mint/internal/api"
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+	"github.com/rwx-research/terraform-provider-mint/internal/sops"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
@@ -20,24 +35,49 @@ import (
 
 // Ensure that the resource satisfies various framework interfaces.
 var (
-	_ resource.Resource              = &SecretResource{}
-	_ resource.ResourceWithConfigure = &SecretResource{}
+	_ resource.Resource                   = &SecretResource{}
+	_ resource.ResourceWithConfigure      = &SecretResource{}
+	_ resource.ResourceWithModifyPlan     = &SecretResource{}
+	_ resource.ResourceWithMoveState      = &SecretResource{}
+	_ resource.ResourceWithUpgradeState   = &SecretResource{}
+	_ resource.ResourceWithValidateConfig = &SecretResource{}
 )
 
+// maxSecretValueBytes is the largest value accepted through secret_value_base64, secret_value_sops
+// or secret_value_age.
+const maxSecretValueBytes = 64 * 1024
+
 func NewSecretResource() resource.Resource {
 	return &SecretResource{}
 }
 
 type SecretResource struct {
-	client api.Client
+	client                  api.Client
+	runDefinitionsDirectory string
+	secretExpiryWarningDays int64
 }
 
 // SecretResourceModel describes the resource data model.
 type SecretResourceModel struct {
-	Vault       types.String `tfsdk:"vault"`
-	Name        types.String `tfsdk:"name"`
-	SecretValue types.String `tfsdk:"secret_value"`
-	Description types.String `tfsdk:"description"`
+	Vault             types.String         `tfsdk:"vault"`
+	Vaults            types.Set            `tfsdk:"vaults"`
+	Name              types.String         `tfsdk:"name"`
+	SecretValue       types.String         `tfsdk:"secret_value"`
+	SecretValueBase64 types.String         `tfsdk:"secret_value_base64"`
+	SecretValueSops   *secretSopsModel     `tfsdk:"secret_value_sops"`
+	SecretValueAge    types.String         `tfsdk:"secret_value_age"`
+	SecretValueLength types.Int64          `tfsdk:"secret_value_length"`
+	SecretValueSHA256 types.String         `tfsdk:"secret_value_sha256"`
+	Description       types.String         `tfsdk:"description"`
+	ExpiresAt         types.String         `tfsdk:"expires_at"`
+	Format            types.String         `tfsdk:"format"`
+	FormatFacts       types.Object         `tfsdk:"format_facts"`
+	Generate          *secretGenerateModel `tfsdk:"generate"`
+	Keepers           types.Map            `tfsdk:"keepers"`
+	ID                types.String         `tfsdk:"id"`
+	CreatedAt         types.String         `tfsdk:"created_at"`
+	UpdatedAt         types.String         `tfsdk:"updated_at"`
+	UpdatedBy         types.String         `tfsdk:"updated_by"`
 }
 
 func (r *SecretResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -46,10 +86,11 @@ func (r *SecretResource) Metadata(ctx context.Context, req resource.MetadataRequ
 
 func (r *SecretResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: secretSchemaVersion,
 		Attributes: map[string]schema.Attribute{
 			"vault": schema.StringAttribute{
-				Description: "The name of a vault in Mint that should hold this secret.",
-				Required:    true,
+				Description: "The name of a vault in Mint that should hold this secret. Exactly one of vault or vaults must be set.",
+				Optional:    true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -59,6 +100,22 @@ func (r *SecretResource) Schema(ctx context.Context, req resource.SchemaRequest,
 						regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
 						"can only include alphanumeric characters, dashes, or underscores",
 					),
+					stringvalidator.ExactlyOneOf(path.MatchRoot("vaults")),
+				},
+			},
+			"vaults": schema.SetAttribute{
+				Description: "The names of several vaults in Mint that should each hold this secret, with the same value and description. Adding a vault creates the secret there and removing one deletes it, leaving the other vaults untouched; for a generated secret, adding a vault generates a new value for every vault.",
+				Optional:    true,
+				ElementType: types.StringType,
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(
+						stringvalidator.LengthAtLeast(1),
+						stringvalidator.RegexMatches(
+							regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
+							"can only include alphanumeric characters, dashes, or underscores",
+						),
+					),
 				},
 			},
 			"name": schema.StringAttribute{
@@ -76,17 +133,168 @@ func (r *SecretResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				},
 			},
 			"secret_value": schema.StringAttribute{
-				Description: "The secret value.",
-				Required:    true,
+				Description: "The secret value. At most one of secret_value, secret_value_base64, secret_value_sops, secret_value_age or generate may be set. When none is set, only the description and expiry of an existing secret are managed: its value is left alone, and destroying the resource leaves the secret in Mint. Whether the value is managed cannot change once the secret has been created.",
+				Optional:    true,
 				Sensitive:   true,
 				Validators: []validator.String{
 					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ConflictsWith(
+						path.MatchRoot("secret_value_base64"),
+						path.MatchRoot("secret_value_sops"),
+						path.MatchRoot("secret_value_age"),
+						path.MatchRoot("generate"),
+					),
 				},
 			},
+			"secret_value_base64": schema.StringAttribute{
+				Description: fmt.Sprintf("The secret value as standard base64, for binary values such as keystores or keyrings, e.g. filebase64(\"keystore.jks\"). Mint stores the decoded bytes, which may be at most %d bytes.", maxSecretValueBytes),
+				Optional:    true,
+				Sensitive:   true,
+				Validators: []validator.String{
+					base64Validator{maxBytes: maxSecretValueBytes},
+					stringvalidator.ConflictsWith(
+						path.MatchRoot("secret_value_sops"),
+						path.MatchRoot("secret_value_age"),
+						path.MatchRoot("generate"),
+					),
+				},
+			},
+			"secret_value_sops": schema.SingleNestedAttribute{
+				Description: fmt.Sprintf("Reads the secret value from a SOPS-encrypted YAML or JSON file, decrypting it inside the provider with the age identities in %s, %s or sops/age/keys.txt in the user's config directory. The plaintext is only sent to Mint and never appears in plan or state; changes to it show in secret_value_sha256.", sops.AgeKeyFileEnv, sops.AgeKeyEnv),
+				Optional:    true,
+				Validators: []validator.Object{
+					objectvalidator.ConflictsWith(path.MatchRoot("secret_value_age"), path.MatchRoot("generate")),
+				},
+				Attributes: map[string]schema.Attribute{
+					"file": schema.StringAttribute{
+						Description: "The path to the encrypted file, e.g. \"${path.module}/secrets.enc.yaml\".",
+						Required:    true,
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+					"key": schema.StringAttribute{
+						Description: "The path to the value in the file, with the keys of nested mappings separated by dots, e.g. \"registry.password\".",
+						Required:    true,
+						Validators: []validator.String{
+							stringvalidator.LengthAtLeast(1),
+						},
+					},
+				},
+			},
+			"secret_value_age": schema.StringAttribute{
+				Description: "The secret value as an ASCII-armored age file, as written by age -a, e.g. file(\"token.age\"). It is decrypted inside the provider with the same age identities as secret_value_sops.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(regexp.MustCompile(`^\s*-----BEGIN AGE ENCRYPTED FILE-----`), "must be an ASCII-armored age file"),
+					stringvalidator.ConflictsWith(path.MatchRoot("generate")),
+				},
+			},
+			"secret_value_length": schema.Int64Attribute{
+				Description: "The length of the secret value in bytes, after decoding or decrypting it. Null for secret_value, which shows as a sensitive change, and for generated secrets.",
+				Computed:    true,
+			},
+			"secret_value_sha256": schema.StringAttribute{
+				Description: "A hex-encoded HMAC-SHA256 of the secret value, after decoding or decrypting it, so that changes can be reviewed in a plan without revealing the value. It is keyed with a random salt chosen when the secret is created and kept out of state, so it cannot be used to check a guess at the value, and is unknown until then. Null for secret_value, which shows as a sensitive change, and for generated secrets.",
+				Computed:    true,
+			},
 			"description": schema.StringAttribute{
-				Description: "An optional description of this secret.",
+				Description: "An optional description of this secret. Changing only the description updates it in place without rewriting the value, so the secret keeps its version.",
 				Optional:    true,
 			},
+			"expires_at": schema.StringAttribute{
+				Description: "When the secret stops working, such as a third-party token's expiry, in RFC 3339 format. Mint keeps it as metadata alongside the description. Plans warn when it is within the provider's secret_expiry_warning_days, and fail once it has passed.",
+				Optional:    true,
+				Validators: []validator.String{
+					rfc3339Validator{},
+				},
+			},
+			"format": schema.StringAttribute{
+				Description: "The format the secret value must be in: pem, ssh_private_key, json, jwt or dotenv. The value is parsed locally, during validation or after decryption, and a malformed value fails the plan without being shown.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(secretFormats...),
+					stringvalidator.ConflictsWith(path.MatchRoot("generate")),
+				},
+			},
+			"format_facts": schema.SingleNestedAttribute{
+				Description: "What can be learned about the secret value from its format without revealing it. Null unless format is set.",
+				Computed:    true,
+				Attributes: map[string]schema.Attribute{
+					"key_type": schema.StringAttribute{
+						Description: "The type and size of the key, such as \"RSA 4096\" or \"ssh-ed25519\", or the signing algorithm of a JWT.",
+						Computed:    true,
+					},
+					"not_after": schema.StringAttribute{
+						Description: "When the earliest certificate in a PEM value expires, or the exp claim of a JWT, in RFC 3339 format.",
+						Computed:    true,
+					},
+				},
+			},
+			"keepers": schema.MapAttribute{
+				Description: "Arbitrary values which generate a new value in place whenever they change. Requires generate.",
+				ElementType: types.StringType,
+				Optional:    true,
+				Validators: []validator.Map{
+					mapvalidator.AlsoRequires(path.MatchRoot("generate")),
+				},
+			},
+			"id": schema.StringAttribute{
+				Description: "The vault and name of the secret, as \"vault/name\". For a secret in several vaults, the vaults are separated by commas.",
+				Computed:    true,
+			},
+			"created_at": schema.StringAttribute{
+				Description: "When the secret was created in Mint, in RFC 3339 format. For a secret in several vaults, the earliest.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"updated_at": schema.StringAttribute{
+				Description: "When the secret was last written in Mint, in RFC 3339 format. For a secret in several vaults, the latest.",
+				Computed:    true,
+			},
+			"updated_by": schema.StringAttribute{
+				Description: "Who last wrote the secret in Mint, as reported by Mint.",
+				Computed:    true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"generate": schema.SingleNestedBlock{
+				Description: "Generates a random value for the secret inside the provider. The value is only sent to Mint and never appears in configuration, plan or state. Any change to the generate block or keepers generates a new value.",
+				Attributes: map[string]schema.Attribute{
+					"length": schema.Int64Attribute{
+						Description: fmt.Sprintf("The number of characters for the alnum encoding, or the number of random bytes before encoding for hex and base64. Default: %d.", defaultGeneratedLength),
+						Optional:    true,
+						Validators: []validator.Int64{
+							int64validator.Between(1, 4096),
+						},
+					},
+					"encoding": schema.StringAttribute{
+						Description: "How the value is encoded: alnum, hex or base64. Default: alnum.",
+						Optional:    true,
+						Validators: []validator.String{
+							stringvalidator.OneOf("alnum", "hex", "base64"),
+						},
+					},
+					"upper": schema.BoolAttribute{
+						Description: "Whether an alnum value includes uppercase letters. Default: true.",
+						Optional:    true,
+					},
+					"lower": schema.BoolAttribute{
+						Description: "Whether an alnum value includes lowercase letters. Default: true.",
+						Optional:    true,
+					},
+					"numeric": schema.BoolAttribute{
+						Description: "Whether an alnum value includes digits. Default: true.",
+						Optional:    true,
+					},
+					"special": schema.BoolAttribute{
+						Description: fmt.Sprintf("Whether an alnum value includes the special characters %s. Default: false.", specialCharacters),
+						Optional:    true,
+					},
+				},
+			},
 		},
 	}
 }
@@ -97,144 +305,984 @@ func (r *SecretResource) Configure(ctx context.Context, req resource.ConfigureRe
 		return
 	}
 
-	client, ok := req.ProviderData.(api.Client)
+	data, ok := req.ProviderData.(MintProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected api.Client, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.runDefinitionsDirectory = data.RunDefinitionsDirectory
+	r.secretExpiryWarningDays = data.SecretExpiryWarningDays
 }
 
-func (r *SecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var err error
-	var plan SecretResourceModel
+func (r *SecretResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config SecretResourceModel
 
-	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	vault := plan.Vault.ValueString()
-	secret := api.Secret{
-		Name:        plan.Name.ValueString(),
-		SecretValue: plan.SecretValue.ValueString(),
-		Description: plan.Description.ValueString(),
+	if !config.managesValue() && config.Description.IsNull() && config.ExpiresAt.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("description"),
+			"Missing Attribute Configuration",
+			"A secret without secret_value, secret_value_base64, secret_value_sops, secret_value_age or generate only manages the metadata of an existing secret, so description or expires_at must be set.",
+		)
 	}
 
-	// Mint's backend only supports upserts to the secrets. As a result, this 'create' operation
-	// could overwrite existing secrets - we protect against this by explicitly checking for the
-	// existence of a secret beforehand.
-	_, err = r.client.GetSecretMetadataInVault(vault, secret)
-	if err == nil {
-		resp.Diagnostics.AddError(
-			"Secret already exists in Vault - please choose a different name or vault",
-			fmt.Sprintf("Vault %q already contains a secret with name %q", vault, secret.Name),
+	if !config.Format.IsNull() && !config.managesValue() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("format"),
+			"Invalid Attribute Combination",
+			"format can only be set along with secret_value, secret_value_base64, secret_value_sops or secret_value_age.",
 		)
+	}
+
+	if value, attribute, known := plaintextSecretValue(config); known && !config.Format.IsNull() && !config.Format.IsUnknown() {
+		if _, err := checkSecretFormat(config.Format.ValueString(), value); err != nil {
+			resp.Diagnostics.AddAttributeError(attribute, "Invalid Secret Format", fmt.Sprintf("The secret value is not valid %s: %s.", config.Format.ValueString(), err))
+		}
+	}
+
+	if config.Generate == nil {
 		return
-	} else if !errors.Is(err, api.ErrNotFound) {
-		resp.Diagnostics.AddError(
-			"Error creating secret in Mint",
-			"Unexpected error: "+err.Error(),
+	}
+
+	encoding := config.Generate.Encoding
+	if !encoding.IsNull() && !encoding.IsUnknown() && encoding.ValueString() != "alnum" && config.Generate.characterClassesSet() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("generate"),
+			"Invalid Attribute Combination",
+			"upper, lower, numeric and special can only be set when encoding is alnum.",
+		)
+	}
+
+	for _, class := range []types.Bool{config.Generate.Upper, config.Generate.Lower, config.Generate.Numeric, config.Generate.Special} {
+		if class.IsUnknown() {
+			return
+		}
+	}
+	if (encoding.IsNull() || encoding.ValueString() == "alnum") && config.Generate.characters() == "" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("generate"),
+			"Invalid Attribute Combination",
+			"At least one of upper, lower, numeric or special must be enabled.",
 		)
+	}
+}
+
+func (r *SecretResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	planSecretValueFacts(ctx, req, resp)
+	planSecretID(ctx, resp)
+	planSecretCreatedAt(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	secret, err = r.client.SetSecretInVault(vault, secret)
-	if err != nil {
+	checkReadOnlyPlan(r.client, "secret", req, resp)
+	checkValueManagement(ctx, req, resp)
+	checkMovedVariable(ctx, req, resp)
+	checkRunDefinitionReferences(ctx, r.runDefinitionsDirectory, rundef.ReferenceKindSecret, req, resp)
+
+	if !req.Plan.Raw.IsNull() {
+		var name, expiresAt types.String
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("name"), &name)...)
+		resp.Diagnostics.Append(resp.Plan.GetAttribute(ctx, path.Root("expires_at"), &expiresAt)...)
+		resp.Diagnostics.Append(checkSecretExpiry(time.Now(), name.ValueString(), expiresAt, r.secretExpiryWarningDays)...)
+	}
+}
+
+// managesValue reports whether the resource writes the secret's value, rather than only managing
+// the metadata of an existing secret.
+func (m SecretResourceModel) managesValue() bool {
+	return !m.SecretValue.IsNull() || !m.SecretValueBase64.IsNull() || m.encrypted() || m.Generate != nil
+}
+
+// checkValueManagement fails a plan that would switch a secret between managing its value and
+// managing only the metadata of an existing secret. Destroying the first deletes the secret and
+// destroying the second leaves it, so the switch would silently change what destroy does.
+func checkValueManagement(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state SecretResourceModel
+
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A refresh that finds a generated value changed blanks generate, but the versions the provider
+	// wrote still show that it owns the value.
+	managed := state.managesValue() || getVaultVersions(ctx, req.Private, state).written()
+
+	switch {
+	case managed && !plan.managesValue():
 		resp.Diagnostics.AddError(
-			"Error creating secret in Mint",
-			"Unexpected error: "+err.Error(),
+			"Cannot stop managing the secret value",
+			fmt.Sprintf("Secret %q was written by Terraform, which deletes it when the resource is destroyed. "+
+				"Keep secret_value, secret_value_base64, secret_value_sops, secret_value_age or generate set, or remove the "+
+				"resource from state before managing only the metadata of the secret.", state.Name.ValueString()),
+		)
+	case !managed && plan.managesValue():
+		resp.Diagnostics.AddError(
+			"Cannot manage the value of an existing secret",
+			fmt.Sprintf("Secret %q existed before Terraform and only its metadata is managed, so destroying the resource "+
+				"leaves it in Mint. To manage its value, delete it from Mint and create it again with its value.", state.Name.ValueString()),
 		)
+	}
+}
+
+// metadataFields returns the metadata fields written for a secret. A secret whose value is managed
+// owns its description and expiry, so both are written and null clears them; a secret whose
+// metadata alone is managed only writes those set in configuration, leaving the others as they are.
+func (m SecretResourceModel) metadataFields() []api.SecretMetadataField {
+	if m.managesValue() {
+		return []api.SecretMetadataField{api.SecretDescriptionField, api.SecretExpiresAtField}
+	}
+
+	var fields []api.SecretMetadataField
+	if !m.Description.IsNull() {
+		fields = append(fields, api.SecretDescriptionField)
+	}
+	if !m.ExpiresAt.IsNull() {
+		fields = append(fields, api.SecretExpiresAtField)
+	}
+
+	return fields
+}
+
+// valueUnchanged reports whether applying plan over state would write the same value, so that only
+// the secret's description and expiry need updating.
+func valueUnchanged(state SecretResourceModel, plan SecretResourceModel) bool {
+	sameSops := (state.SecretValueSops == nil) == (plan.SecretValueSops == nil) &&
+		(state.SecretValueSops == nil || *state.SecretValueSops == *plan.SecretValueSops)
+	sameGenerate := (state.Generate == nil) == (plan.Generate == nil) &&
+		(state.Generate == nil || *state.Generate == *plan.Generate)
+
+	return state.SecretValue.Equal(plan.SecretValue) &&
+		state.SecretValueBase64.Equal(plan.SecretValueBase64) &&
+		state.SecretValueAge.Equal(plan.SecretValueAge) &&
+		state.SecretValueSHA256.Equal(plan.SecretValueSHA256) &&
+		state.Keepers.Equal(plan.Keepers) &&
+		sameSops && sameGenerate
+}
+
+// secretWrite is the value written to Mint for a plan. A generated value is generated once and
+// written to every vault.
+type secretWrite struct {
+	value    string
+	encoding string
+
+	// content identifies the value in fingerprints without revealing it to anything but a digest.
+	content     string
+	name        string
+	description string
+}
+
+// fingerprint identifies the write to a vault for idempotency.
+func (w secretWrite) fingerprint(vault string) string {
+	return fingerprintWrite(vault, w.name, w.content, w.description)
+}
+
+// plaintext returns the value written, decoded if it is sent base64-encoded.
+func (w secretWrite) plaintext() []byte {
+	if w.encoding == "base64" {
+		decoded, _ := base64.StdEncoding.DecodeString(w.value)
+		return decoded
+	}
+
+	return []byte(w.value)
+}
+
+// secretValue returns the value to write for a plan, generating one if the secret has a generate
+// block.
+func (r *SecretResource) secretValue(ctx context.Context, plan SecretResourceModel) (secretWrite, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	write := secretWrite{name: plan.Name.ValueString(), description: plan.Description.ValueString()}
+
+	switch {
+	case plan.Generate != nil:
+		value, err := plan.Generate.generate()
+		if err != nil {
+			diags.AddError(
+				"Error generating secret value",
+				"Unexpected error: "+err.Error(),
+			)
+			return secretWrite{}, diags
+		}
+
+		write.value = value
+		write.content, diags = generatedWriteFingerprint(ctx, *plan.Generate, plan.Keepers)
+	case plan.encrypted():
+		// Decrypted values may be binary, so they are always sent base64-encoded.
+		var decrypted []byte
+		decrypted, _, diags = decryptSecretValue(plan)
+		if diags.HasError() {
+			return secretWrite{}, diags
+		}
+
+		write.value, write.encoding = base64.StdEncoding.EncodeToString(decrypted), "base64"
+		write.content = "base64:" + write.value
+	case !plan.SecretValueBase64.IsNull():
+		// Re-encode the decoded value so that equivalent encodings are written identically.
+		decoded, err := base64.StdEncoding.DecodeString(plan.SecretValueBase64.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("secret_value_base64"),
+				"Invalid Base64",
+				"Unable to decode secret_value_base64: "+err.Error(),
+			)
+			return secretWrite{}, diags
+		}
+
+		write.value, write.encoding = base64.StdEncoding.EncodeToString(decoded), "base64"
+		write.content = "base64:" + write.value
+	default:
+		write.value = plan.SecretValue.ValueString()
+		write.content = write.value
+	}
+
+	return write, diags
+}
+
+// secretValueFacts returns the length in bytes and keyed digest of a secret's configured value.
+// They stand in for binary and encrypted values in plans. Encrypted values are only decrypted when
+// planning, so their facts are left as they are. secret_value already shows as a sensitive change,
+// so it has none.
+func secretValueFacts(model SecretResourceModel, salt []byte) (types.Int64, types.String) {
+	switch {
+	case model.encrypted():
+		return model.SecretValueLength, model.SecretValueSHA256
+	case model.SecretValueBase64.IsUnknown():
+		return types.Int64Unknown(), types.StringUnknown()
+	case model.SecretValueBase64.IsNull():
+		return types.Int64Null(), types.StringNull()
+	}
+
+	value, err := base64.StdEncoding.DecodeString(model.SecretValueBase64.ValueString())
+	if err != nil {
+		return types.Int64Null(), types.StringNull()
+	}
+
+	return valueFacts(value, salt)
+}
+
+// valueFacts returns the length of a value and its HMAC-SHA256 keyed with salt. A plain digest
+// would let anyone who can read the plan or state confirm a guess at the value. The digest is null
+// without a salt.
+func valueFacts(value []byte, salt []byte) (types.Int64, types.String) {
+	length := types.Int64Value(int64(len(value)))
+	if salt == nil {
+		return length, types.StringNull()
+	}
+
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(value)
+	return length, types.StringValue(hex.EncodeToString(mac.Sum(nil)))
+}
+
+// privateValueSaltKey holds the salt that a secret's value digest is keyed with.
+const privateValueSaltKey = "value_salt"
+
+// getValueSalt returns the salt recorded in private state, or nil if there is none.
+func getValueSalt(ctx context.Context, private privateGetter) []byte {
+	value, diags := private.GetKey(ctx, privateValueSaltKey)
+	if diags.HasError() || value == nil {
+		return nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(value, &encoded); err != nil {
+		return nil
+	}
+
+	salt, err := hex.DecodeString(encoded)
+	if err != nil || len(salt) == 0 {
+		return nil
+	}
+
+	return salt
+}
+
+// newValueSalt generates a salt and records it in private state.
+func newValueSalt(ctx context.Context, private privateSetter) ([]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		diags.AddError("Unable to generate salt", "Unexpected error: "+err.Error())
+		return nil, diags
+	}
+
+	encoded, err := json.Marshal(hex.EncodeToString(salt))
+	if err != nil {
+		diags.AddError("Unable to record salt", "Unexpected error: "+err.Error())
+		return nil, diags
+	}
+
+	return salt, private.SetKey(ctx, privateValueSaltKey, encoded)
+}
+
+// plaintextSecretValue returns the value of secret_value, or the decoded value of
+// secret_value_base64, along with the attribute it came from. It returns false when neither is set
+// and known.
+func plaintextSecretValue(model SecretResourceModel) ([]byte, path.Path, bool) {
+	switch {
+	case !model.SecretValue.IsNull() && !model.SecretValue.IsUnknown():
+		return []byte(model.SecretValue.ValueString()), path.Root("secret_value"), true
+	case !model.SecretValueBase64.IsNull() && !model.SecretValueBase64.IsUnknown():
+		decoded, err := base64.StdEncoding.DecodeString(model.SecretValueBase64.ValueString())
+		return decoded, path.Root("secret_value_base64"), err == nil
+	default:
+		return nil, path.Empty(), false
+	}
+}
+
+// planSecretValueFacts sets secret_value_length, secret_value_sha256 and format_facts from the
+// planned value, decrypting it if needed so that changes to an encrypted file show in the plan and
+// its format can be checked.
+func planSecretValueFacts(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if resp.Plan.Raw.IsNull() {
 		return
 	}
 
-	resp.Private.SetKey(ctx, "version", []byte(strconv.Itoa(secret.Version)))
+	var plan SecretResourceModel
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// A secret without a salt, such as one moved from a variable, gets one with this update.
+	salt := getValueSalt(ctx, req.Private)
+	if salt == nil && !req.State.Raw.IsNull() {
+		var diags diag.Diagnostics
+		salt, diags = newValueSalt(ctx, resp.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	value, attribute, known := plaintextSecretValue(plan)
+	if plan.encrypted() {
+		var diags diag.Diagnostics
+		value, known, diags = decryptSecretValue(plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		attribute = path.Root("secret_value_age")
+		if plan.SecretValueSops != nil {
+			attribute = path.Root("secret_value_sops")
+		}
+
+		plan.SecretValueLength, plan.SecretValueSHA256 = types.Int64Unknown(), types.StringUnknown()
+		if known {
+			plan.SecretValueLength, plan.SecretValueSHA256 = valueFacts(value, salt)
+		}
+	} else {
+		plan.SecretValueLength, plan.SecretValueSHA256 = secretValueFacts(plan, salt)
+	}
+
+	// Create does not see the planned private state, so a new secret's salt is chosen when it is
+	// created and its digest is not known until then.
+	if req.State.Raw.IsNull() && !plan.SecretValueLength.IsNull() {
+		plan.SecretValueSHA256 = types.StringUnknown()
+	}
+
+	switch {
+	case plan.Format.IsNull() || !plan.managesValue() || plan.Generate != nil:
+		plan.FormatFacts = types.ObjectNull(formatFactsAttrTypes)
+	case plan.Format.IsUnknown() || !known:
+		plan.FormatFacts = types.ObjectUnknown(formatFactsAttrTypes)
+	default:
+		facts, err := checkSecretFormat(plan.Format.ValueString(), value)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(attribute, "Invalid Secret Format", fmt.Sprintf("The secret value is not valid %s: %s.", plan.Format.ValueString(), err))
+			return
+		}
+		plan.FormatFacts = facts.object()
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
 }
 
-func (r *SecretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var err error
-	var state SecretResourceModel
+// planSecretID sets id from the planned vaults and name, so that it is known whenever they are.
+func planSecretID(ctx context.Context, resp *resource.ModifyPlanResponse) {
+	if resp.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan SecretResourceModel
+
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	known := !plan.Name.IsUnknown() && !plan.Vault.IsUnknown() && !plan.Vaults.IsUnknown()
+	for _, vault := range plan.Vaults.Elements() {
+		known = known && !vault.IsUnknown()
+	}
+	if !known {
+		plan.ID = types.StringUnknown()
+	} else {
+		vaults, diags := plan.vaultNames(ctx)
+		resp.Diagnostics.Append(diags...)
+		plan.ID = resourceID(vaults, plan.Name.ValueString())
+	}
 
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+// planSecretCreatedAt leaves created_at unknown when the vaults change. It is the earliest creation
+// across the vaults, so adding or removing one can change it.
+func planSecretCreatedAt(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || resp.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state SecretResourceModel
+
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() || plan.ID.Equal(state.ID) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("created_at"), types.StringUnknown())...)
+}
+
+func (r *SecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startSpan(ctx, "mint_secret", "Create")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var plan SecretResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vaults, diags := plan.vaultNames(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	span.SetAttributes(vaultsAttribute(vaults))
+
+	if !plan.managesValue() {
+		for _, vault := range vaults {
+			if !r.createDescription(ctx, vault, plan, &resp.Diagnostics) {
+				return
+			}
+		}
+
+		r.readTimestamps(ctx, vaults, &plan, &resp.Diagnostics)
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	write, diags := r.secretValue(ctx, plan)
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	vault := state.Vault.ValueString()
+	salt, diags := newValueSalt(ctx, resp.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.SecretValueSHA256.IsUnknown() {
+		_, plan.SecretValueSHA256 = valueFacts(write.plaintext(), salt)
+	}
+
+	versions := vaultVersions{}
+	var created, unconfirmed []string
+	for _, vault := range vaults {
+		// A new secret has no pending writes, so its own private state stands in for the prior one.
+		version, ok := r.createSecret(ctx, vault, plan, write, resp.Private, resp.Private, &resp.Diagnostics)
+		if !ok {
+			// Mint may still have applied a create whose key is pending.
+			if _, pending := pendingIdempotencyKeyAt(ctx, resp.Private, secretIdempotencyKey(plan, vault), write.fingerprint(vault)); pending {
+				unconfirmed = append(unconfirmed, vault)
+			}
+			break
+		}
+
+		versions[vault] = vaultVersion{Version: version, ObservedVersion: version}
+		created = append(created, vault)
+	}
+	written := append(created, unconfirmed...)
+
+	if resp.Diagnostics.HasError() {
+		// Terraform taints a resource whose create fails but still has state, so recording the
+		// vaults that were, or may have been, written means the next apply deletes them before
+		// starting over. Those creates are sent with new idempotency keys, so they write the secret
+		// again.
+		if len(written) == 0 {
+			return
+		}
+
+		if plan.multipleVaults() {
+			plan.Vaults, diags = types.SetValueFrom(ctx, types.StringType, written)
+			resp.Diagnostics.Append(diags...)
+		}
+	}
+
+	r.readTimestamps(ctx, created, &plan, &resp.Diagnostics)
+	plan.ID = resourceID(written, plan.Name.ValueString())
+	resp.Diagnostics.Append(setVaultVersions(ctx, resp.Private, plan, versions)...)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// createSecret writes a new secret to a vault, returning its version. The create's idempotency key
+// is recorded in next until Mint confirms it, so that a create whose response was lost is sent
+// again with the same key and replayed, rather than refused because the secret now exists.
+func (r *SecretResource) createSecret(ctx context.Context, vault string, plan SecretResourceModel, write secretWrite, prior privateGetter, next privateSetter, diags *diag.Diagnostics) (int, bool) {
 	secret := api.Secret{
-		Name: state.Name.ValueString(),
+		Name:        plan.Name.ValueString(),
+		SecretValue: write.value,
+		Encoding:    write.encoding,
+		Description: plan.Description.ValueString(),
+		ExpiresAt:   expiresAtValue(plan.ExpiresAt),
+	}
+
+	privateKey := secretIdempotencyKey(plan, vault)
+	if _, pending := pendingIdempotencyKeyAt(ctx, prior, privateKey, write.fingerprint(vault)); !pending && !checkSecretAbsent(ctx, r.client, vault, secret.Name, diags) {
+		return 0, false
+	}
+
+	key, d := updateIdempotencyKeyAt(ctx, prior, next, privateKey, write.fingerprint(vault))
+	diags.Append(d...)
+	if diags.HasError() {
+		return 0, false
+	}
+	ctx = api.WithIdempotencyKey(ctx, key)
+
+	// The key is forgotten once the create is confirmed, so a later create of the same secret is
+	// always written rather than replayed.
+	secret.CreateOnly = true
+
+	secret, err := r.client.SetSecretInVault(ctx, vault, secret)
+	if err != nil {
+		if errors.Is(err, api.ErrConflict) {
+			diags.Append(clearIdempotencyKeyAt(ctx, next, privateKey)...)
+			addSecretExistsError(diags, vault, plan.Name.ValueString())
+			return 0, false
+		}
+
+		diags.AddError(
+			"Error creating secret in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return 0, false
+	}
+
+	diags.Append(clearIdempotencyKeyAt(ctx, next, privateKey)...)
+
+	return secret.Version, true
+}
+
+// checkSecretAbsent fails a create if the secret already exists in the vault. Mint's backend only
+// supports upserts to the secrets, so a create could otherwise overwrite an existing secret. Writes
+// also ask Mint to reject them with create_only, but that isn't relied on alone.
+func checkSecretAbsent(ctx context.Context, client api.Client, vault string, name string, diags *diag.Diagnostics) bool {
+	_, err := client.GetSecretMetadataInVault(ctx, vault, api.Secret{Name: name})
+	if err == nil {
+		addSecretExistsError(diags, vault, name)
+		return false
+	}
+	if !errors.Is(err, api.ErrNotFound) {
+		diags.AddError(
+			"Error creating secret in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return false
 	}
 
-	secret, err = r.client.GetSecretMetadataInVault(vault, secret)
+	return true
+}
+
+// addSecretExistsError reports a create that would overwrite an existing secret.
+func addSecretExistsError(diags *diag.Diagnostics, vault string, name string) {
+	diags.AddError(
+		"Secret already exists in Vault - please choose a different name or vault",
+		fmt.Sprintf("Vault %q already contains a secret with name %q", vault, name),
+	)
+}
+
+// createDescription starts managing the description and expiry of a secret that already exists in
+// a vault, leaving its value alone.
+func (r *SecretResource) createDescription(ctx context.Context, vault string, plan SecretResourceModel, diags *diag.Diagnostics) bool {
+	_, err := r.client.GetSecretMetadataInVault(ctx, vault, api.Secret{Name: plan.Name.ValueString()})
 	if err != nil {
 		if errors.Is(err, api.ErrNotFound) {
-			resp.State.RemoveResource(ctx)
-			return
+			diags.AddError(
+				"Secret does not exist in Vault",
+				fmt.Sprintf("Vault %q has no secret with name %q. Set secret_value, secret_value_base64, secret_value_sops, secret_value_age or generate to create it.", vault, plan.Name.ValueString()),
+			)
+			return false
 		}
 
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error reading secret metadata from Mint",
 			"Unexpected error: "+err.Error(),
 		)
-		return
+		return false
 	}
 
-	if secret.Description != "" {
-		state.Description = types.StringValue(secret.Description)
+	secret := api.Secret{
+		Name:        plan.Name.ValueString(),
+		Description: plan.Description.ValueString(),
+		ExpiresAt:   expiresAtValue(plan.ExpiresAt),
 	}
 
-	version, diags := req.Private.GetKey(ctx, "version")
+	return r.updateMetadata(ctx, vault, secret, plan.metadataFields(), diags)
+}
+
+// updateMetadata writes the given fields of a secret's description and expiry without its value, so
+// that the secret keeps its version and jobs holding the value are unaffected.
+func (r *SecretResource) updateMetadata(ctx context.Context, vault string, secret api.Secret, fields []api.SecretMetadataField, diags *diag.Diagnostics) bool {
+	if len(fields) == 0 {
+		return true
+	}
+
+	if _, err := r.client.UpdateSecretMetadataInVault(ctx, vault, secret, fields...); err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			diags.AddError(
+				"Secret does not exist in Vault",
+				fmt.Sprintf("Vault %q has no secret with name %q.", vault, secret.Name),
+			)
+			return false
+		}
+
+		diags.AddError(
+			"Error updating secret metadata in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return false
+	}
+
+	return true
+}
+
+// readTimestamps sets a secret's id and timestamps after writing it to its vaults. The secret was
+// written either way, so failing to read them back only warns.
+func (r *SecretResource) readTimestamps(ctx context.Context, vaults []string, model *SecretResourceModel, diags *diag.Diagnostics) {
+	var t timestamps
+	failed := false
+	for _, vault := range vaults {
+		secret, err := r.client.GetSecretMetadataInVault(ctx, vault, api.Secret{Name: model.Name.ValueString()})
+		if err != nil {
+			diags.AddWarning(
+				"Unable to read secret timestamps from Mint",
+				fmt.Sprintf("Secret %q in vault %q was written, but reading it back failed: %s. Its timestamps are updated on the next refresh.", model.Name.ValueString(), vault, err),
+			)
+			failed = true
+			continue
+		}
+
+		t.add(secret.CreatedAt, secret.UpdatedAt, secret.UpdatedBy)
+	}
+
+	createdAt := model.CreatedAt
+	model.ID = resourceID(vaults, model.Name.ValueString())
+	model.CreatedAt, model.UpdatedAt, model.UpdatedBy = t.values()
+	if failed {
+		model.CreatedAt = plannedCreatedAt(createdAt)
+	}
+}
+
+func (r *SecretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_secret", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var state SecretResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vaults, diags := state.vaultNames(ctx)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	span.SetAttributes(vaultsAttribute(vaults))
+
+	versions := getVaultVersions(ctx, req.Private, state)
+	observed := vaultVersions{}
+	var found []string
+	var t timestamps
+	drifted := false
+
+	for _, vault := range vaults {
+		secret, err := r.client.GetSecretMetadataInVault(ctx, vault, api.Secret{Name: state.Name.ValueString()})
+		if err != nil {
+			// A vault that lost the secret drops out of the set, so the next plan writes it again.
+			if errors.Is(err, api.ErrNotFound) {
+				continue
+			}
+
+			resp.Diagnostics.AddError(
+				"Error reading secret metadata from Mint",
+				"Unexpected error: "+err.Error(),
+			)
+			return
+		}
+
+		// The description and expiry are written to every vault alike, so the first is representative.
+		// A secret whose metadata alone is managed only tracks the fields set in configuration.
+		if len(found) == 0 {
+			if secret.Description != "" && (state.managesValue() || !state.Description.IsNull()) {
+				state.Description = types.StringValue(secret.Description)
+			}
+			if state.managesValue() || !state.ExpiresAt.IsNull() {
+				state.ExpiresAt = expiresAtFromAPI(state.ExpiresAt, secret.ExpiresAt)
+			}
+		}
+
+		found = append(found, vault)
+		t.add(secret.CreatedAt, secret.UpdatedAt, secret.UpdatedBy)
+		drifted = drifted || secret.Version != versions[vault].Version
+
+		// Remember the version we saw so that a subsequent update only overwrites the secret if
+		// nobody else has changed it in the meantime.
+		observed[vault] = vaultVersion{Version: versions[vault].Version, ObservedVersion: secret.Version}
+	}
+
+	// A secret moved from a variable is written by the next apply, which then removes the variable.
+	_, moved := getMovedVariable(ctx, req.Private)
+	drifted = drifted || moved
+
+	if len(found) == 0 && !moved {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if state.multipleVaults() && len(found) < len(vaults) {
+		vaults = found
+		state.Vaults, diags = types.SetValueFrom(ctx, types.StringType, found)
+		resp.Diagnostics.Append(diags...)
+	}
+	state.ID = resourceID(vaults, state.Name.ValueString())
+	state.CreatedAt, state.UpdatedAt, state.UpdatedBy = t.values()
+
+	state.SecretValueLength, state.SecretValueSHA256 = secretValueFacts(state, getValueSalt(ctx, req.Private))
 
-	if strconv.Itoa(secret.Version) != string(version) {
-		state.SecretValue = types.StringValue("")
+	// The value was changed outside of Terraform. Blanking what we wrote makes the next plan
+	// write it again; for a generated secret, that means generating a new value, and for an
+	// encrypted one, forgetting the digest of what was decrypted.
+	if state.managesValue() && drifted {
+		switch {
+		case state.Generate != nil:
+			state.Generate = nil
+		case state.encrypted():
+			state.SecretValueLength, state.SecretValueSHA256 = types.Int64Null(), types.StringNull()
+		case !state.SecretValueBase64.IsNull():
+			state.SecretValueBase64 = types.StringValue("")
+		default:
+			state.SecretValue = types.StringValue("")
+		}
 	}
 
+	resp.Diagnostics.Append(setVaultVersions(ctx, resp.Private, state, observed)...)
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *SecretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var err error
-	var plan SecretResourceModel
+	ctx, span := startSpan(ctx, "mint_secret", "Update")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var plan, state SecretResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	vault := plan.Vault.ValueString()
+	priorVaults, diags := state.vaultNames(ctx)
+	resp.Diagnostics.Append(diags...)
+	vaults, diags := plan.vaultNames(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	span.SetAttributes(vaultsAttribute(vaults))
+
+	removed, added := removedVaults(priorVaults, vaults), removedVaults(vaults, priorVaults)
+	kept := removedVaults(vaults, added)
+
+	// A generated value is never kept, so adding a vault to a generated secret generates a new value
+	// for every vault; otherwise the value is only rewritten when it changed, and a change to the
+	// metadata alone keeps each secret's version. A secret moved from a variable is always written.
+	moved, isMoved := getMovedVariable(ctx, req.Private)
+	rewrite := plan.managesValue() && (isMoved || !valueUnchanged(state, plan) || (plan.Generate != nil && len(added) > 0))
+	metadataChanged := !plan.Description.Equal(state.Description) || !plan.ExpiresAt.Equal(state.ExpiresAt)
+
+	var write secretWrite
+	if plan.managesValue() && (rewrite || len(added) > 0) {
+		write, diags = r.secretValue(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	versions := getVaultVersions(ctx, req.Private, state)
+	current := append([]string{}, priorVaults...)
+
+	for _, vault := range removed {
+		// Secrets whose metadata alone was managed existed before Terraform, so they are left in Mint.
+		if state.managesValue() {
+			if err := r.client.DeleteSecretInVault(ctx, vault, api.Secret{Name: state.Name.ValueString()}); err != nil {
+				resp.Diagnostics.AddError(
+					"Error deleting secret in Mint",
+					"Unexpected error: "+err.Error(),
+				)
+				break
+			}
+		}
+
+		delete(versions, vault)
+		current = removedVaults(current, []string{vault})
+		resp.Diagnostics.Append(clearIdempotencyKeyAt(ctx, resp.Private, secretIdempotencyKey(plan, vault))...)
+	}
+
+	for _, vault := range added {
+		if resp.Diagnostics.HasError() {
+			break
+		}
+
+		if !plan.managesValue() {
+			if !r.createDescription(ctx, vault, plan, &resp.Diagnostics) {
+				break
+			}
+		} else {
+			version, ok := r.createSecret(ctx, vault, plan, write, req.Private, resp.Private, &resp.Diagnostics)
+			if !ok {
+				break
+			}
+			versions[vault] = vaultVersion{Version: version, ObservedVersion: version}
+		}
+
+		current = append(current, vault)
+	}
+
+	for _, vault := range kept {
+		if resp.Diagnostics.HasError() {
+			break
+		}
+
+		if rewrite {
+			var version int
+			var ok bool
+
+			// A secret moved from a variable is created by its first write, which fails rather than
+			// overwrite a secret of the same name.
+			if isMoved && versions[vault].Version == 0 {
+				version, ok = r.createSecret(ctx, vault, plan, write, req.Private, resp.Private, &resp.Diagnostics)
+			} else {
+				version, ok = r.updateSecret(ctx, vault, plan, write, versions[vault], req.Private, resp.Private, &resp.Diagnostics)
+			}
+			if ok {
+				versions[vault] = vaultVersion{Version: version, ObservedVersion: version}
+			}
+		} else if metadataChanged {
+			secret := api.Secret{
+				Name:        plan.Name.ValueString(),
+				Description: plan.Description.ValueString(),
+				ExpiresAt:   expiresAtValue(plan.ExpiresAt),
+			}
+			r.updateMetadata(ctx, vault, secret, plan.metadataFields(), &resp.Diagnostics)
+		}
+	}
+
+	// The secret now holds the value of the variable it was moved from, so the variable can go.
+	if isMoved && !resp.Diagnostics.HasError() {
+		r.removeMovedVariable(ctx, moved, resp.Private, &resp.Diagnostics)
+	}
+
+	resp.Diagnostics.Append(setVaultVersions(ctx, resp.Private, plan, versions)...)
+
+	if resp.Diagnostics.HasError() {
+		// Record which vaults hold the secret now, so that the next plan only redoes what failed.
+		if plan.multipleVaults() {
+			sort.Strings(current)
+			currentVaults, diags := types.SetValueFrom(ctx, types.StringType, current)
+			resp.Diagnostics.Append(diags...)
+			resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("vaults"), currentVaults)...)
+		}
+		return
+	}
+
+	r.readTimestamps(ctx, vaults, &plan, &resp.Diagnostics)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// updateSecret overwrites a secret's value in a vault, returning its new version. The write is
+// rejected if the secret changed since Terraform last read it.
+func (r *SecretResource) updateSecret(ctx context.Context, vault string, plan SecretResourceModel, write secretWrite, version vaultVersion, prior privateGetter, next privateSetter, diags *diag.Diagnostics) (int, bool) {
 	secret := api.Secret{
-		Name:        plan.Name.ValueString(),
-		SecretValue: plan.SecretValue.ValueString(),
-		Description: plan.Description.ValueString(),
+		Name:            plan.Name.ValueString(),
+		SecretValue:     write.value,
+		Encoding:        write.encoding,
+		Description:     plan.Description.ValueString(),
+		ExpiresAt:       expiresAtValue(plan.ExpiresAt),
+		ExpectedVersion: version.expectedVersion(),
 	}
 
-	secret, err = r.client.SetSecretInVault(vault, secret)
+	privateKey := secretIdempotencyKey(plan, vault)
+	key, d := updateIdempotencyKeyAt(ctx, prior, next, privateKey, write.fingerprint(vault))
+	diags.Append(d...)
+	if diags.HasError() {
+		return 0, false
+	}
+	ctx = api.WithIdempotencyKey(ctx, key)
+
+	secret, err := r.client.SetSecretInVault(ctx, vault, secret)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		if errors.Is(err, api.ErrConflict) {
+			diags.AddError(
+				"Secret was changed outside of Terraform",
+				fmt.Sprintf("Secret %q in vault %q was modified after Terraform last read it and has not been overwritten. "+
+					"Run Terraform again to refresh the secret and review the change before applying.", plan.Name.ValueString(), vault),
+			)
+			return 0, false
+		}
+
+		diags.AddError(
 			"Error updating secret in Mint",
 			"Unexpected error: "+err.Error(),
 		)
-		return
+		return 0, false
 	}
 
-	resp.Private.SetKey(ctx, "version", []byte(strconv.Itoa(secret.Version)))
+	diags.Append(clearIdempotencyKeyAt(ctx, next, privateKey)...)
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+	return secret.Version, true
 }
 
 func (r *SecretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var err error
+	ctx, span := startSpan(ctx, "mint_secret", "Delete")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	var state SecretResourceModel
 
 	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
@@ -242,16 +1290,31 @@ func (r *SecretResource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
-	vault := state.Vault.ValueString()
-	secret := api.Secret{
-		Name: state.Name.ValueString(),
+	vaults, diags := state.vaultNames(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	span.SetAttributes(vaultsAttribute(vaults))
 
-	if err = r.client.DeleteSecretInVault(vault, secret); err != nil {
-		resp.Diagnostics.AddError(
-			"Error deleting secret in Mint",
-			"Unexpected error: "+err.Error(),
-		)
+	// The secret existed before Terraform managed its description, so it is left in Mint.
+	if !state.managesValue() {
 		return
 	}
+
+	// Mint treats deleting a missing secret as success, so a failed delete can simply be retried.
+	for _, vault := range vaults {
+		if err := r.client.DeleteSecretInVault(ctx, vault, api.Secret{Name: state.Name.ValueString()}); err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting secret in Mint",
+				"Unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Destroying a secret that was moved from a variable before it was applied destroys the variable.
+	if moved, ok := getMovedVariable(ctx, req.Private); ok {
+		r.removeMovedVariable(ctx, moved, resp.Private, &resp.Diagnostics)
+	}
 }
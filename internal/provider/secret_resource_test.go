@@ -1,8 +1,15 @@
 package provider
 
 import (
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -25,6 +32,9 @@ resource "mint_secret" "test" {
 					resource.TestCheckResourceAttr("mint_secret.test", "name", "test-secret"),
 					resource.TestCheckResourceAttr("mint_secret.test", "secret_value", "foo"),
 					resource.TestCheckResourceAttr("mint_secret.test", "description", "a description"),
+					resource.TestCheckResourceAttr("mint_secret.test", "id", "terraform_provider_testing/test-secret"),
+					resource.TestCheckResourceAttrSet("mint_secret.test", "created_at"),
+					resource.TestCheckResourceAttrSet("mint_secret.test", "updated_at"),
 				),
 			},
 			// Update and Read testing
@@ -47,3 +57,478 @@ resource "mint_secret" "test" {
 		},
 	})
 }
+
+func TestSecretResourceGenerate(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_secret" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-generated-secret"
+
+  generate {
+    length   = 32
+    encoding = "hex"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mint_secret.test", "secret_value"),
+					resource.TestCheckResourceAttr("mint_secret.test", "generate.length", "32"),
+				),
+			},
+			// Regeneration testing
+			{
+				Config: providerConfig + `
+resource "mint_secret" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-generated-secret"
+
+  generate {
+    length   = 32
+    encoding = "hex"
+  }
+
+  keepers = {
+    rotation = "1"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mint_secret.test", "secret_value"),
+					resource.TestCheckResourceAttr("mint_secret.test", "keepers.rotation", "1"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestSecretResourceBase64(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_secret" "test" {
+  vault               = "terraform_provider_testing"
+  name                = "test-binary-secret"
+  secret_value_base64 = base64encode("foo")
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mint_secret.test", "secret_value"),
+					resource.TestCheckResourceAttr("mint_secret.test", "secret_value_length", "3"),
+					resource.TestMatchResourceAttr("mint_secret.test", "secret_value_sha256", regexp.MustCompile(`^[0-9a-f]{64}$`)),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestSecretResourceVaults(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_secret" "test" {
+  vaults       = ["terraform_provider_testing", "terraform_provider_testing_2"]
+  name         = "test-replicated-secret"
+  secret_value = "foo"
+  description  = "a description"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mint_secret.test", "vault"),
+					resource.TestCheckResourceAttr("mint_secret.test", "vaults.#", "2"),
+					resource.TestCheckTypeSetElemAttr("mint_secret.test", "vaults.*", "terraform_provider_testing_2"),
+					resource.TestCheckResourceAttr("mint_secret.test", "id", "terraform_provider_testing,terraform_provider_testing_2/test-replicated-secret"),
+				),
+			},
+			// Removing and adding vaults in place
+			{
+				Config: providerConfig + `
+resource "mint_secret" "test" {
+  vaults       = ["terraform_provider_testing", "terraform_provider_testing_3"]
+  name         = "test-replicated-secret"
+  secret_value = "foo"
+  description  = "a description"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_secret.test", "vaults.#", "2"),
+					resource.TestCheckTypeSetElemAttr("mint_secret.test", "vaults.*", "terraform_provider_testing_3"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestSecretValueFacts(t *testing.T) {
+	cases := []struct {
+		name   string
+		model  SecretResourceModel
+		salt   []byte
+		length types.Int64
+		sha256 types.String
+	}{
+		{
+			name:   "secret_value shows as sensitive instead",
+			model:  SecretResourceModel{SecretValue: types.StringValue("foo"), SecretValueBase64: types.StringNull()},
+			length: types.Int64Null(),
+			sha256: types.StringNull(),
+		},
+		{
+			name:   "secret_value_base64 is decoded and keyed with the salt",
+			model:  SecretResourceModel{SecretValue: types.StringNull(), SecretValueBase64: types.StringValue("Zm9v")},
+			salt:   []byte("salt"),
+			length: types.Int64Value(3),
+			sha256: types.StringValue("6a9534d88e984dfcea835f190147b72b3f647fdcc2409e5b8be8b331ec7fe8a5"),
+		},
+		{
+			name:   "no salt",
+			model:  SecretResourceModel{SecretValue: types.StringNull(), SecretValueBase64: types.StringValue("Zm9v")},
+			length: types.Int64Value(3),
+			sha256: types.StringNull(),
+		},
+		{
+			name:   "unknown",
+			model:  SecretResourceModel{SecretValue: types.StringNull(), SecretValueBase64: types.StringUnknown()},
+			length: types.Int64Unknown(),
+			sha256: types.StringUnknown(),
+		},
+		{
+			name:   "generated",
+			model:  SecretResourceModel{SecretValue: types.StringNull(), SecretValueBase64: types.StringNull(), Generate: &secretGenerateModel{}},
+			length: types.Int64Null(),
+			sha256: types.StringNull(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			length, sha256 := secretValueFacts(c.model, c.salt)
+			if !length.Equal(c.length) {
+				t.Errorf("expected length %s, got %s", c.length, length)
+			}
+			if !sha256.Equal(c.sha256) {
+				t.Errorf("expected sha256 %s, got %s", c.sha256, sha256)
+			}
+		})
+	}
+}
+
+func TestValueUnchanged(t *testing.T) {
+	state := SecretResourceModel{
+		SecretValue:       types.StringValue("foo"),
+		SecretValueSHA256: types.StringValue("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"),
+		Description:       types.StringValue("before"),
+		Keepers:           types.MapNull(types.StringType),
+	}
+
+	descriptionOnly := state
+	descriptionOnly.Description = types.StringValue("after")
+	if !valueUnchanged(state, descriptionOnly) {
+		t.Error("expected a description change to leave the value unchanged")
+	}
+
+	newValue := descriptionOnly
+	newValue.SecretValue = types.StringValue("bar")
+	if valueUnchanged(state, newValue) {
+		t.Error("expected a new secret_value to change the value")
+	}
+
+	// Read blanks the value when it was changed outside of Terraform, which must rewrite it.
+	drifted := state
+	drifted.SecretValue = types.StringValue("")
+	if valueUnchanged(drifted, descriptionOnly) {
+		t.Error("expected a drifted value to be rewritten")
+	}
+
+	sops := SecretResourceModel{
+		SecretValueSops:   &secretSopsModel{File: types.StringValue("secrets.enc.yaml"), Key: types.StringValue("api_token")},
+		SecretValueSHA256: types.StringValue("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"),
+	}
+	reencrypted := sops
+	reencrypted.SecretValueSHA256 = types.StringValue("fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9")
+	if valueUnchanged(sops, reencrypted) {
+		t.Error("expected a change to the decrypted value to change the value")
+	}
+
+	generated := SecretResourceModel{Generate: &secretGenerateModel{Length: types.Int64Value(32)}, Keepers: types.MapNull(types.StringType)}
+	relabelled := generated
+	relabelled.Generate = &secretGenerateModel{Length: types.Int64Value(32)}
+	relabelled.Description = types.StringValue("signing key")
+	if !valueUnchanged(generated, relabelled) {
+		t.Error("expected a description change to keep a generated value")
+	}
+}
+
+func TestSecretCreateIsNeverReplayed(t *testing.T) {
+	p := newTestProvider(t)
+	config := p.config("mint_secret", map[string]tftypes.Value{
+		"vault":        tfString("default"),
+		"name":         tfString("API_KEY"),
+		"secret_value": tfString("hunter2"),
+	})
+
+	state, diags := p.apply("mint_secret", testState{}, config)
+	p.requireNoErrors(diags)
+	p.requireNoErrors(p.destroy("mint_secret", state))
+
+	// The same create again, as after a replacement, writes the secret rather than replaying the
+	// first create's success.
+	_, diags = p.apply("mint_secret", testState{}, config)
+	p.requireNoErrors(diags)
+
+	if _, ok := p.mint.secrets["default/API_KEY"]; !ok {
+		t.Error("expected the recreated secret to be written to Mint")
+	}
+}
+
+func TestSecretCreateChecksForExistingSecret(t *testing.T) {
+	p := newTestProvider(t)
+	p.mint.ignoreCreateOnly = true
+	p.mint.secrets["default/API_KEY"] = api.Secret{Name: "API_KEY", SecretValue: "existing", Version: 1}
+
+	_, diags := p.apply("mint_secret", testState{}, p.config("mint_secret", map[string]tftypes.Value{
+		"vault":        tfString("default"),
+		"name":         tfString("API_KEY"),
+		"secret_value": tfString("hunter2"),
+	}))
+
+	if !strings.Contains(diagnosticsString(diags), "Secret already exists in Vault") {
+		t.Errorf("expected the create to be refused, got: %s", diagnosticsString(diags))
+	}
+	if p.mint.secrets["default/API_KEY"].SecretValue != "existing" {
+		t.Error("expected the existing secret not to be overwritten")
+	}
+}
+
+func TestSecretCreateWithLostResponse(t *testing.T) {
+	p := newTestProvider(t)
+	config := p.config("mint_secret", map[string]tftypes.Value{
+		"vault":        tfString("default"),
+		"name":         tfString("API_KEY"),
+		"secret_value": tfString("hunter2"),
+	})
+
+	p.mint.loseWriteResponses = true
+	state, diags := p.apply("mint_secret", testState{}, config)
+	if !hasErrors(diags) {
+		t.Fatal("expected the create to fail")
+	}
+	if state.value.IsNull() {
+		t.Fatal("expected the secret Mint may have created to be recorded")
+	}
+
+	// Terraform replaces the tainted secret on the next apply, deleting what Mint created rather
+	// than refusing to create a secret that already exists.
+	p.mint.loseWriteResponses = false
+	p.requireNoErrors(p.destroy("mint_secret", state))
+	_, diags = p.apply("mint_secret", testState{}, config)
+	p.requireNoErrors(diags)
+
+	if p.mint.secrets["default/API_KEY"].SecretValue != "hunter2" {
+		t.Error("expected the secret to be written to Mint")
+	}
+}
+
+func TestSecretValueDigestIsKeyed(t *testing.T) {
+	p := newTestProvider(t)
+	configWith := func(value string) tftypes.Value {
+		return p.config("mint_secret", map[string]tftypes.Value{
+			"vault":               tfString("default"),
+			"name":                tfString("SIGNING_KEY"),
+			"secret_value_base64": tfString(value),
+		})
+	}
+
+	state, diags := p.apply("mint_secret", testState{}, configWith("Zm9v"))
+	p.requireNoErrors(diags)
+
+	digest := stateAttribute(state, "secret_value_sha256")
+	if !digest.IsKnown() || digest.IsNull() {
+		t.Fatalf("expected a digest once the secret is created, got %s", digest)
+	}
+	if digest.Equal(tfString("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae")) {
+		t.Error("expected the digest to be keyed rather than the value's plain SHA-256")
+	}
+
+	// The salt is kept with the resource, so the digest is stable and an unchanged value is not
+	// written again.
+	state = p.read("mint_secret", state)
+	state, diags = p.apply("mint_secret", state, configWith("Zm9v"))
+	p.requireNoErrors(diags)
+	if !stateAttribute(state, "secret_value_sha256").Equal(digest) {
+		t.Errorf("expected the digest to be kept, got %s", stateAttribute(state, "secret_value_sha256"))
+	}
+	if version := p.mint.secrets["default/SIGNING_KEY"].Version; version != 1 {
+		t.Errorf("expected the unchanged value not to be rewritten, got version %d", version)
+	}
+
+	state, diags = p.apply("mint_secret", state, configWith("YmFy"))
+	p.requireNoErrors(diags)
+	if stateAttribute(state, "secret_value_sha256").Equal(digest) {
+		t.Error("expected a new value to change the digest")
+	}
+}
+
+func TestSecretMetadataOnlyKeepsUnsetFields(t *testing.T) {
+	expiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		attribute string
+		value     string
+		check     func(t *testing.T, secret api.Secret)
+	}{
+		{
+			name:      "expires_at only",
+			attribute: "expires_at",
+			value:     "2031-06-01T00:00:00Z",
+			check: func(t *testing.T, secret api.Secret) {
+				if secret.Description != "existing description" {
+					t.Errorf("expected the description to be kept, got %q", secret.Description)
+				}
+				if secret.ExpiresAt == nil || !secret.ExpiresAt.Equal(time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("expected the expiry to be set, got %v", secret.ExpiresAt)
+				}
+			},
+		},
+		{
+			name:      "description only",
+			attribute: "description",
+			value:     "new description",
+			check: func(t *testing.T, secret api.Secret) {
+				if secret.Description != "new description" {
+					t.Errorf("expected the description to be set, got %q", secret.Description)
+				}
+				if secret.ExpiresAt == nil || !secret.ExpiresAt.Equal(expiry) {
+					t.Errorf("expected the expiry to be kept, got %v", secret.ExpiresAt)
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := newTestProvider(t)
+			p.mint.secrets["default/API_KEY"] = api.Secret{Name: "API_KEY", SecretValue: "existing", Description: "existing description", ExpiresAt: &expiry, Version: 1}
+
+			config := p.config("mint_secret", map[string]tftypes.Value{
+				"vault":     tfString("default"),
+				"name":      tfString("API_KEY"),
+				c.attribute: tfString(c.value),
+			})
+
+			state, diags := p.apply("mint_secret", testState{}, config)
+			p.requireNoErrors(diags)
+			c.check(t, p.mint.secrets["default/API_KEY"])
+
+			// Refreshing and applying again leaves the field that isn't configured alone.
+			state = p.read("mint_secret", state)
+			_, diags = p.apply("mint_secret", state, config)
+			p.requireNoErrors(diags)
+			c.check(t, p.mint.secrets["default/API_KEY"])
+		})
+	}
+}
+
+func TestSecretUpdateKeepsCreatedAtWhenReadBackFails(t *testing.T) {
+	p := newTestProvider(t)
+	configWith := func(value string) tftypes.Value {
+		return p.config("mint_secret", map[string]tftypes.Value{
+			"vault":        tfString("default"),
+			"name":         tfString("API_KEY"),
+			"secret_value": tfString(value),
+		})
+	}
+
+	state, diags := p.apply("mint_secret", testState{}, configWith("hunter2"))
+	p.requireNoErrors(diags)
+	createdAt := stateAttribute(state, "created_at")
+
+	p.mint.failReads["default"] = true
+	state, diags = p.apply("mint_secret", state, configWith("hunter3"))
+	p.requireNoErrors(diags)
+
+	if !stateAttribute(state, "created_at").Equal(createdAt) {
+		t.Errorf("expected created_at to be kept, got %s", stateAttribute(state, "created_at"))
+	}
+}
+
+func TestSecretCannotSwitchValueManagement(t *testing.T) {
+	managed := map[string]tftypes.Value{
+		"vault":        tfString("default"),
+		"name":         tfString("API_KEY"),
+		"secret_value": tfString("hunter2"),
+		"description":  tfString("the API key"),
+	}
+	metadataOnly := map[string]tftypes.Value{
+		"vault":       tfString("default"),
+		"name":        tfString("API_KEY"),
+		"description": tfString("the API key"),
+	}
+
+	t.Run("value removed", func(t *testing.T) {
+		p := newTestProvider(t)
+
+		state, diags := p.apply("mint_secret", testState{}, p.config("mint_secret", managed))
+		p.requireNoErrors(diags)
+		_, diags = p.apply("mint_secret", state, p.config("mint_secret", metadataOnly))
+
+		if !strings.Contains(diagnosticsString(diags), "Cannot stop managing the secret value") {
+			t.Errorf("expected the plan to be refused, got: %s", diagnosticsString(diags))
+		}
+	})
+
+	t.Run("value added", func(t *testing.T) {
+		p := newTestProvider(t)
+		p.mint.secrets["default/API_KEY"] = api.Secret{Name: "API_KEY", SecretValue: "existing", Version: 1}
+
+		state, diags := p.apply("mint_secret", testState{}, p.config("mint_secret", metadataOnly))
+		p.requireNoErrors(diags)
+		_, diags = p.apply("mint_secret", state, p.config("mint_secret", managed))
+
+		if !strings.Contains(diagnosticsString(diags), "Cannot manage the value of an existing secret") {
+			t.Errorf("expected the plan to be refused, got: %s", diagnosticsString(diags))
+		}
+		if p.mint.secrets["default/API_KEY"].SecretValue != "existing" {
+			t.Error("expected the existing secret not to be overwritten")
+		}
+	})
+
+	t.Run("generated value changed outside Terraform", func(t *testing.T) {
+		p := newTestProvider(t)
+		generateType := p.schemas["mint_secret"].ValueType().(tftypes.Object).AttributeTypes["generate"]
+		config := p.config("mint_secret", map[string]tftypes.Value{
+			"vault":    tfString("default"),
+			"name":     tfString("API_KEY"),
+			"generate": objectWith(generateType, nil),
+		})
+
+		state, diags := p.apply("mint_secret", testState{}, config)
+		p.requireNoErrors(diags)
+
+		secret := p.mint.secrets["default/API_KEY"]
+		secret.SecretValue, secret.Version = "changed", secret.Version+1
+		p.mint.secrets["default/API_KEY"] = secret
+
+		state = p.read("mint_secret", state)
+		_, diags = p.apply("mint_secret", state, config)
+		p.requireNoErrors(diags)
+
+		if p.mint.secrets["default/API_KEY"].SecretValue == "changed" {
+			t.Error("expected a new value to be generated")
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// privateVaultVersionsKey holds the versions of a secret written to a set of vaults, keyed by vault.
+// Secrets in a single vault keep using privateVersionKey and privateObservedVersionKey.
+const privateVaultVersionsKey = "vault_versions"
+
+// vaultVersion is what the provider knows about a secret's version in one vault.
+type vaultVersion struct {
+	// Version is the version last written by the provider.
+	Version int `json:"version"`
+	// ObservedVersion is the version seen during the last refresh.
+	ObservedVersion int `json:"observed_version"`
+}
+
+// vaultVersions maps the name of each vault holding a secret to its version there.
+type vaultVersions map[string]vaultVersion
+
+// vaultNames returns the vaults a secret is written to, in order.
+func (m SecretResourceModel) vaultNames(ctx context.Context) ([]string, diag.Diagnostics) {
+	if !m.Vault.IsNull() {
+		return []string{m.Vault.ValueString()}, nil
+	}
+
+	return setStrings(ctx, m.Vaults)
+}
+
+// multipleVaults reports whether a secret uses the vaults set rather than vault.
+func (m SecretResourceModel) multipleVaults() bool {
+	return !m.Vaults.IsNull()
+}
+
+// setStrings returns the elements of a set of strings, sorted.
+func setStrings(ctx context.Context, set types.Set) ([]string, diag.Diagnostics) {
+	var elements []string
+	diags := set.ElementsAs(ctx, &elements, false)
+	sort.Strings(elements)
+
+	return elements, diags
+}
+
+// removedVaults returns the vaults in prior that are not in next.
+func removedVaults(prior []string, next []string) []string {
+	kept := make(map[string]bool, len(next))
+	for _, vault := range next {
+		kept[vault] = true
+	}
+
+	var removed []string
+	for _, vault := range prior {
+		if !kept[vault] {
+			removed = append(removed, vault)
+		}
+	}
+
+	return removed
+}
+
+// getVaultVersions reads the versions of a secret from private state. Missing or malformed entries
+// read as version 0, which Mint treats as "no expectation".
+func getVaultVersions(ctx context.Context, private privateGetter, model SecretResourceModel) vaultVersions {
+	if !model.multipleVaults() {
+		return vaultVersions{model.Vault.ValueString(): {
+			Version:         getPrivateInt(ctx, private, privateVersionKey),
+			ObservedVersion: getPrivateInt(ctx, private, privateObservedVersionKey),
+		}}
+	}
+
+	versions := vaultVersions{}
+	if value, diags := private.GetKey(ctx, privateVaultVersionsKey); !diags.HasError() && value != nil {
+		if err := json.Unmarshal(value, &versions); err != nil {
+			return vaultVersions{}
+		}
+	}
+
+	return versions
+}
+
+// setVaultVersions stores the versions of a secret in private state.
+func setVaultVersions(ctx context.Context, private privateSetter, model SecretResourceModel, versions vaultVersions) diag.Diagnostics {
+	if !model.multipleVaults() {
+		version := versions[model.Vault.ValueString()]
+
+		diags := setPrivateInt(ctx, private, privateVersionKey, version.Version)
+		diags.Append(setPrivateInt(ctx, private, privateObservedVersionKey, version.ObservedVersion)...)
+		return diags
+	}
+
+	encoded, err := json.Marshal(versions)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Unable to record secret versions", "Unexpected error: "+err.Error())
+		return diags
+	}
+
+	return private.SetKey(ctx, privateVaultVersionsKey, encoded)
+}
+
+// written reports whether the provider wrote the secret's value to any of its vaults.
+func (v vaultVersions) written() bool {
+	for _, version := range v {
+		if version.Version != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expectedVersion returns the version a write to the vault expects to replace: the version seen
+// during the last refresh, or the one last written if the secret hasn't been refreshed since.
+func (v vaultVersion) expectedVersion() int {
+	if v.ObservedVersion != 0 {
+		return v.ObservedVersion
+	}
+
+	return v.Version
+}
+
+// secretIdempotencyKey returns the private state key holding the pending write to a vault. Each
+// vault of a set has its own, as writes to them succeed or fail independently.
+func secretIdempotencyKey(model SecretResourceModel, vault string) string {
+	if !model.multipleVaults() {
+		return privateIdempotencyKey
+	}
+
+	return privateIdempotencyKey + "/" + vault
+}
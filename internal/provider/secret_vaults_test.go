@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// privateState is an in-memory stand-in for a resource's private state.
+type privateState map[string][]byte
+
+func (p privateState) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p privateState) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}
+
+func TestRemovedVaults(t *testing.T) {
+	prior := []string{"a", "b", "c"}
+	next := []string{"b", "c", "d"}
+
+	if removed := removedVaults(prior, next); !reflect.DeepEqual(removed, []string{"a"}) {
+		t.Errorf("expected a to be removed, got %v", removed)
+	}
+	if added := removedVaults(next, prior); !reflect.DeepEqual(added, []string{"d"}) {
+		t.Errorf("expected d to be added, got %v", added)
+	}
+	if removed := removedVaults(prior, prior); len(removed) != 0 {
+		t.Errorf("expected nothing to be removed, got %v", removed)
+	}
+}
+
+func TestVaultVersions(t *testing.T) {
+	ctx := context.Background()
+	versions := vaultVersions{
+		"a": {Version: 3, ObservedVersion: 4},
+		"b": {Version: 7, ObservedVersion: 7},
+	}
+
+	t.Run("single vault", func(t *testing.T) {
+		model := SecretResourceModel{Vault: types.StringValue("a"), Vaults: types.SetNull(types.StringType)}
+		private := privateState{}
+
+		if diags := setVaultVersions(ctx, private, model, versions); diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+
+		// Secrets in a single vault keep the keys written before vaults existed.
+		if string(private[privateVersionKey]) != "3" || string(private[privateObservedVersionKey]) != "4" {
+			t.Errorf("expected version 3 and observed version 4, got %q and %q", private[privateVersionKey], private[privateObservedVersionKey])
+		}
+		if got := getVaultVersions(ctx, private, model); !reflect.DeepEqual(got, vaultVersions{"a": versions["a"]}) {
+			t.Errorf("expected %v, got %v", versions["a"], got)
+		}
+	})
+
+	t.Run("several vaults", func(t *testing.T) {
+		vaults, _ := types.SetValueFrom(ctx, types.StringType, []string{"a", "b"})
+		model := SecretResourceModel{Vault: types.StringNull(), Vaults: vaults}
+		private := privateState{}
+
+		if diags := setVaultVersions(ctx, private, model, versions); diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+
+		if got := getVaultVersions(ctx, private, model); !reflect.DeepEqual(got, versions) {
+			t.Errorf("expected %v, got %v", versions, got)
+		}
+		if key := secretIdempotencyKey(model, "b"); key != "idempotency_key/b" {
+			t.Errorf("expected a key per vault, got %q", key)
+		}
+	})
+
+	t.Run("expected version", func(t *testing.T) {
+		if expected := (vaultVersion{Version: 3}).expectedVersion(); expected != 3 {
+			t.Errorf("expected the written version before a refresh, got %d", expected)
+		}
+		if expected := versions["a"].expectedVersion(); expected != 4 {
+			t.Errorf("expected the observed version, got %d", expected)
+		}
+	})
+}
+
+func TestSecretVaultsPartialCreate(t *testing.T) {
+	p := newTestProvider(t)
+	config := p.config("mint_secret", map[string]tftypes.Value{
+		"vaults":       tfStringSet("staging", "production"),
+		"name":         tfString("API_KEY"),
+		"secret_value": tfString("hunter2"),
+	})
+
+	p.mint.failWrites["staging"] = true
+	state, diags := p.apply("mint_secret", testState{}, config)
+	if !hasErrors(diags) {
+		t.Fatal("expected the create to fail")
+	}
+	// The failed create may still have been applied by Mint, so it is recorded along with the vault
+	// that was written.
+	if !sameValue(stateAttribute(state, "vaults"), tfStringSet("staging", "production")) {
+		t.Fatalf("expected the vaults written to be recorded, got %v", stateAttribute(state, "vaults"))
+	}
+
+	// Terraform replaces the tainted secret on the next apply: it deletes what was written, then
+	// creates the secret in every vault again.
+	p.mint.failWrites["staging"] = false
+	p.requireNoErrors(p.destroy("mint_secret", state))
+	_, diags = p.apply("mint_secret", testState{}, config)
+	p.requireNoErrors(diags)
+
+	for _, vault := range []string{"staging", "production"} {
+		if p.mint.secrets[vault+"/API_KEY"].SecretValue != "hunter2" {
+			t.Errorf("expected the secret to be written to %s", vault)
+		}
+	}
+}
+
+func TestSecretVaultsAddedWithLostResponse(t *testing.T) {
+	p := newTestProvider(t)
+	configWith := func(vaults ...string) tftypes.Value {
+		return p.config("mint_secret", map[string]tftypes.Value{
+			"vaults":       tfStringSet(vaults...),
+			"name":         tfString("API_KEY"),
+			"secret_value": tfString("hunter2"),
+		})
+	}
+
+	state, diags := p.apply("mint_secret", testState{}, configWith("production"))
+	p.requireNoErrors(diags)
+
+	p.mint.loseWriteResponses = true
+	state, diags = p.apply("mint_secret", state, configWith("production", "staging"))
+	if !hasErrors(diags) {
+		t.Fatal("expected the update to fail")
+	}
+
+	// The next apply sends the create again with its key, so Mint replays it instead of refusing
+	// it because the secret now exists.
+	p.mint.loseWriteResponses = false
+	state, diags = p.apply("mint_secret", state, configWith("production", "staging"))
+	p.requireNoErrors(diags)
+
+	if !sameValue(stateAttribute(state, "vaults"), tfStringSet("production", "staging")) {
+		t.Errorf("expected both vaults to be recorded, got %v", stateAttribute(state, "vaults"))
+	}
+	if version := p.mint.secrets["staging/API_KEY"].Version; version != 1 {
+		t.Errorf("expected the create to be written once, got version %d", version)
+	}
+}
+
+func TestSecretVaultsReAdded(t *testing.T) {
+	p := newTestProvider(t)
+	configWith := func(vaults ...string) tftypes.Value {
+		return p.config("mint_secret", map[string]tftypes.Value{
+			"vaults":       tfStringSet(vaults...),
+			"name":         tfString("API_KEY"),
+			"secret_value": tfString("hunter2"),
+		})
+	}
+
+	state, diags := p.apply("mint_secret", testState{}, configWith("staging", "production"))
+	p.requireNoErrors(diags)
+	state, diags = p.apply("mint_secret", state, configWith("production"))
+	p.requireNoErrors(diags)
+
+	if _, ok := p.mint.secrets["staging/API_KEY"]; ok {
+		t.Fatal("expected the secret to be deleted from the removed vault")
+	}
+
+	_, diags = p.apply("mint_secret", state, configWith("staging", "production"))
+	p.requireNoErrors(diags)
+
+	if p.mint.secrets["staging/API_KEY"].SecretValue != "hunter2" {
+		t.Error("expected the secret to be written to the vault added back")
+	}
+}
+
+func TestSecretVaultsOldestRemoved(t *testing.T) {
+	p := newTestProvider(t)
+	configWith := func(vaults ...string) tftypes.Value {
+		return p.config("mint_secret", map[string]tftypes.Value{
+			"vaults":       tfStringSet(vaults...),
+			"name":         tfString("API_KEY"),
+			"secret_value": tfString("hunter2"),
+		})
+	}
+
+	state, diags := p.apply("mint_secret", testState{}, configWith("staging"))
+	p.requireNoErrors(diags)
+	state, diags = p.apply("mint_secret", state, configWith("staging", "production"))
+	p.requireNoErrors(diags)
+	state, diags = p.apply("mint_secret", state, configWith("production"))
+	p.requireNoErrors(diags)
+
+	createdAt := p.mint.secrets["production/API_KEY"].CreatedAt.Format(time.RFC3339)
+	if !stateAttribute(state, "created_at").Equal(tfString(createdAt)) {
+		t.Errorf("expected created_at to be when the secret was created in production, got %s", stateAttribute(state, "created_at"))
+	}
+}
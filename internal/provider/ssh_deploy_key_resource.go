@@ -0,0 +1,403 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// Ensure that the resource satisfies various framework interfaces.
+var (
+	_ resource.Resource                   = &SSHDeployKeyResource{}
+	_ resource.ResourceWithConfigure      = &SSHDeployKeyResource{}
+	_ resource.ResourceWithModifyPlan     = &SSHDeployKeyResource{}
+	_ resource.ResourceWithValidateConfig = &SSHDeployKeyResource{}
+)
+
+const defaultRSABits = 4096
+
+func NewSSHDeployKeyResource() resource.Resource {
+	return &SSHDeployKeyResource{}
+}
+
+// SSHDeployKeyResource generates an SSH keypair and stores the private key as a secret in a vault.
+// The private key never leaves the provider other than in the request to Mint, so it is absent
+// from plan and state; only the public key and its fingerprint are recorded.
+type SSHDeployKeyResource struct {
+	client                  api.Client
+	runDefinitionsDirectory string
+}
+
+// SSHDeployKeyResourceModel describes the resource data model.
+type SSHDeployKeyResourceModel struct {
+	Vault             types.String `tfsdk:"vault"`
+	Name              types.String `tfsdk:"name"`
+	Algorithm         types.String `tfsdk:"algorithm"`
+	RSABits           types.Int64  `tfsdk:"rsa_bits"`
+	Keepers           types.Map    `tfsdk:"keepers"`
+	PublicKey         types.String `tfsdk:"public_key"`
+	FingerprintSHA256 types.String `tfsdk:"fingerprint_sha256"`
+}
+
+func (r *SSHDeployKeyResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ssh_deploy_key"
+}
+
+func (r *SSHDeployKeyResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Generates an SSH keypair inside the provider and stores the private key as a secret in a Mint vault. Only the public key and its fingerprint are exposed, for example to register a GitHub deploy key, so the private key never appears in configuration, plan or state.",
+		Attributes: map[string]schema.Attribute{
+			"vault": schema.StringAttribute{
+				Description: "The name of a vault in Mint that should hold the private key.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
+						"can only include alphanumeric characters, dashes, or underscores",
+					),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "The name of the secret that holds the private key.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.RegexMatches(
+						regexp.MustCompile(`^[a-zA-Z0-9_-]*$`),
+						"can only include alphanumeric characters, dashes, or underscores",
+					),
+				},
+			},
+			"algorithm": schema.StringAttribute{
+				Description: "The key algorithm: ed25519 or rsa. Default: ed25519.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("ed25519"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.OneOf("ed25519", "rsa"),
+				},
+			},
+			"rsa_bits": schema.Int64Attribute{
+				Description: fmt.Sprintf("The size of an RSA key in bits: 2048, 3072 or 4096. Default: %d. Only valid when algorithm is rsa.", defaultRSABits),
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Int64{
+					int64validator.OneOf(2048, 3072, 4096),
+				},
+			},
+			"keepers": schema.MapAttribute{
+				Description: "Arbitrary values which generate a new keypair whenever they change, e.g. the output of a time_rotating resource.",
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				Description: "The public key in OpenSSH authorized_keys format.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"fingerprint_sha256": schema.StringAttribute{
+				Description: "The SHA256 fingerprint of the public key, as shown by GitHub and ssh-keygen -l.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *SSHDeployKeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(MintProviderData)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+	r.runDefinitionsDirectory = data.RunDefinitionsDirectory
+}
+
+func (r *SSHDeployKeyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config SSHDeployKeyResourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.RSABits.IsNull() && !config.Algorithm.IsUnknown() && config.Algorithm.ValueString() != "rsa" {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rsa_bits"),
+			"Invalid Attribute Combination",
+			"rsa_bits can only be set when algorithm is rsa.",
+		)
+	}
+}
+
+func (r *SSHDeployKeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	r.planRegeneration(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	checkReadOnlyPlan(r.client, "SSH deploy key", req, resp)
+	checkRunDefinitionReferences(ctx, r.runDefinitionsDirectory, rundef.ReferenceKindSecret, req, resp)
+}
+
+// planRegeneration replaces the keypair when the secret holding the private key was changed
+// outside of Terraform, since the recorded public key may no longer match it.
+func (r *SSHDeployKeyResource) planRegeneration(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	version := getPrivateInt(ctx, req.Private, privateVersionKey)
+	observedVersion := getPrivateInt(ctx, req.Private, privateObservedVersionKey)
+	if observedVersion == 0 || observedVersion == version {
+		return
+	}
+
+	var plan SSHDeployKeyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.PublicKey = types.StringUnknown()
+	plan.FingerprintSHA256 = types.StringUnknown()
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	resp.RequiresReplace = append(resp.RequiresReplace, path.Root("public_key"))
+}
+
+func (r *SSHDeployKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startSpan(ctx, "mint_ssh_deploy_key", "Create")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var plan SSHDeployKeyResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vault := plan.Vault.ValueString()
+	span.SetAttributes(tracing.VaultKey.String(vault))
+
+	bits := int(plan.RSABits.ValueInt64())
+	if bits == 0 {
+		bits = defaultRSABits
+	}
+
+	key, err := generateSSHKey(plan.Algorithm.ValueString(), bits)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error generating SSH key",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	secret := api.Secret{
+		Name:        plan.Name.ValueString(),
+		SecretValue: key.privateKey,
+		Description: "SSH deploy key " + key.fingerprint,
+		CreateOnly:  true,
+	}
+	if !checkSecretAbsent(ctx, r.client, vault, secret.Name, &resp.Diagnostics) {
+		return
+	}
+
+	secret, err = r.client.SetSecretInVault(ctx, vault, secret)
+	if err != nil {
+		if errors.Is(err, api.ErrConflict) {
+			addSecretExistsError(&resp.Diagnostics, vault, plan.Name.ValueString())
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Error creating SSH deploy key in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(setPrivateInt(ctx, resp.Private, privateVersionKey, secret.Version)...)
+	resp.Diagnostics.Append(setPrivateInt(ctx, resp.Private, privateObservedVersionKey, secret.Version)...)
+
+	plan.PublicKey = types.StringValue(key.publicKey)
+	plan.FingerprintSHA256 = types.StringValue(key.fingerprint)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *SSHDeployKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_ssh_deploy_key", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var state SSHDeployKeyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vault := state.Vault.ValueString()
+	span.SetAttributes(tracing.VaultKey.String(vault))
+	secret := api.Secret{
+		Name: state.Name.ValueString(),
+	}
+
+	secret, err = r.client.GetSecretMetadataInVault(ctx, vault, secret)
+	if err != nil {
+		if errors.Is(err, api.ErrNotFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+
+		resp.Diagnostics.AddError(
+			"Error reading SSH deploy key metadata from Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// A version other than the one we wrote means the private key was replaced outside of
+	// Terraform; ModifyPlan generates a new keypair when it sees this.
+	resp.Diagnostics.Append(setPrivateInt(ctx, resp.Private, privateObservedVersionKey, secret.Version)...)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *SSHDeployKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan SSHDeployKeyResourceModel
+
+	// Every attribute that affects the key requires replacement, so there is nothing to send to Mint.
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *SSHDeployKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startSpan(ctx, "mint_ssh_deploy_key", "Delete")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
+	var err error
+	var state SSHDeployKeyResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	vault := state.Vault.ValueString()
+	span.SetAttributes(tracing.VaultKey.String(vault))
+	secret := api.Secret{
+		Name: state.Name.ValueString(),
+	}
+
+	if err = r.client.DeleteSecretInVault(ctx, vault, secret); err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting SSH deploy key in Mint",
+			"Unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+type sshKey struct {
+	privateKey  string
+	publicKey   string
+	fingerprint string
+}
+
+// generateSSHKey generates a keypair, returning the private key in OpenSSH PEM format.
+func generateSSHKey(algorithm string, bits int) (sshKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch algorithm {
+	case "ed25519":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		private, err = rsa.GenerateKey(rand.Reader, bits)
+	default:
+		err = fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return sshKey{}, err
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		return sshKey{}, err
+	}
+
+	public, err := ssh.NewPublicKey(private.Public())
+	if err != nil {
+		return sshKey{}, err
+	}
+
+	return sshKey{
+		privateKey:  string(pem.EncodeToMemory(block)),
+		publicKey:   strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(public)), "\n"),
+		fingerprint: ssh.FingerprintSHA256(public),
+	}, nil
+}
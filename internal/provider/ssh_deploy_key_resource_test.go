@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHDeployKeyResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_ssh_deploy_key" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-ssh-deploy-key"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_ssh_deploy_key.test", "algorithm", "ed25519"),
+					resource.TestMatchResourceAttr("mint_ssh_deploy_key.test", "public_key", regexp.MustCompile(`^ssh-ed25519 `)),
+					resource.TestMatchResourceAttr("mint_ssh_deploy_key.test", "fingerprint_sha256", regexp.MustCompile(`^SHA256:`)),
+				),
+			},
+			// Rotation testing
+			{
+				Config: providerConfig + `
+resource "mint_ssh_deploy_key" "test" {
+  vault     = "terraform_provider_testing"
+  name      = "test-ssh-deploy-key"
+  algorithm = "rsa"
+  rsa_bits  = 2048
+  keepers = {
+    rotation = "1"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_ssh_deploy_key.test", "algorithm", "rsa"),
+					resource.TestMatchResourceAttr("mint_ssh_deploy_key.test", "public_key", regexp.MustCompile(`^ssh-rsa `)),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestGenerateSSHKey(t *testing.T) {
+	for _, algorithm := range []string{"ed25519", "rsa"} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := generateSSHKey(algorithm, 2048)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			signer, err := ssh.ParsePrivateKey([]byte(key.privateKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			public, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key.publicKey))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(signer.PublicKey().Marshal()) != string(public.Marshal()) {
+				t.Error("expected the public key to match the private key")
+			}
+			if key.fingerprint != ssh.FingerprintSHA256(public) {
+				t.Errorf("expected fingerprint %s, got %s", ssh.FingerprintSHA256(public), key.fingerprint)
+			}
+		})
+	}
+}
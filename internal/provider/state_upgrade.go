@@ -0,0 +1,98 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Schema versions of the resources' state. State written before resources declared a version is
+// version 0. Bump a resource's version whenever a release changes its attributes, and have every
+// earlier version in its UpgradeState lead to the new one.
+//
+// Private state is not versioned: Terraform keeps it as is across upgrades, so the meaning of
+// privateVersionKey and the other private keys must stay compatible.
+const (
+	secretSchemaVersion   int64 = 1
+	variableSchemaVersion int64 = 1
+)
+
+// stateUpgrader upgrades a resource's state from an earlier schema version, once it has been
+// decoded into the current schema.
+type stateUpgrader func(ctx context.Context, resp *resource.UpgradeStateResponse)
+
+// upgradeFrom returns a state upgrader that decodes state written with an earlier schema version
+// into the current schema, which only added attributes since: attributes the state lacks are null,
+// and those the current schema no longer has are dropped. The upgrade function then fills in
+// whatever can be derived from the rest of the state.
+func upgradeFrom(upgrade stateUpgrader) resource.StateUpgrader {
+	return resource.StateUpgrader{
+		StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			if req.RawState == nil {
+				resp.Diagnostics.AddError("Unable to upgrade state", "The prior state is missing.")
+				return
+			}
+
+			value, err := req.RawState.UnmarshalWithOpts(resp.State.Schema.Type().TerraformType(ctx), tfprotov6.UnmarshalOpts{
+				ValueFromJSONOpts: tftypes.ValueFromJSONOpts{IgnoreUndefinedAttributes: true},
+			})
+			if err != nil {
+				resp.Diagnostics.AddError("Unable to upgrade state", "Unexpected error: "+err.Error())
+				return
+			}
+			resp.State.Raw = value
+
+			if upgrade != nil {
+				upgrade(ctx, resp)
+			}
+		},
+	}
+}
+
+// UpgradeState upgrades the state of secrets written by earlier versions of the provider.
+func (r *SecretResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: upgradeFrom(upgradeSecret),
+	}
+}
+
+// upgradeSecret fills in id for state written before version 1, which only held vault, name,
+// secret_value and description, so that upgrading alone doesn't show it as changing in the next
+// plan. Timestamps come from Mint on the next refresh.
+func upgradeSecret(ctx context.Context, resp *resource.UpgradeStateResponse) {
+	var state SecretResourceModel
+
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = resourceID([]string{state.Vault.ValueString()}, state.Name.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// UpgradeState upgrades the state of variables written by earlier versions of the provider.
+func (r *VariableResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: upgradeFrom(upgradeVariable),
+	}
+}
+
+// upgradeVariable fills in id for state written before version 1, which only held vault, name and
+// value. Timestamps come from Mint on the
+// next refresh.
+func upgradeVariable(ctx context.Context, resp *resource.UpgradeStateResponse) {
+	var state VariableResourceModel
+
+	resp.Diagnostics.Append(resp.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = resourceID([]string{state.Vault.ValueString()}, state.Name.ValueString())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
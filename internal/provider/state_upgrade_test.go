@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestUpgradeState(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		resource resource.Resource
+		typeName string
+		state    string
+		expected map[string]string
+		null     []string
+	}{
+		{
+			name:     "secret written before schema versions",
+			resource: NewSecretResource(),
+			typeName: "mint_secret",
+			state:    `{"vault":"default","name":"TOKEN","secret_value":"foo","description":"a token"}`,
+			expected: map[string]string{
+				"vault":        "default",
+				"name":         "TOKEN",
+				"secret_value": "foo",
+				"description":  "a token",
+				"id":           "default/TOKEN",
+			},
+			null: []string{"vaults", "secret_value_length", "secret_value_sha256", "created_at"},
+		},
+		{
+			name:     "secret without a description written before schema versions",
+			resource: NewSecretResource(),
+			typeName: "mint_secret",
+			state:    `{"vault":"default","name":"TOKEN","secret_value":"foo","description":null}`,
+			expected: map[string]string{
+				"secret_value": "foo",
+				"id":           "default/TOKEN",
+			},
+			null: []string{"description"},
+		},
+		{
+			name:     "variable written before schema versions",
+			resource: NewVariableResource(),
+			typeName: "mint_variable",
+			state:    `{"vault":"default","name":"HOSTNAME","value":"internal.example.com"}`,
+			expected: map[string]string{
+				"vault": "default",
+				"name":  "HOSTNAME",
+				"value": "internal.example.com",
+				"id":    "default/HOSTNAME",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var schemaResp resource.SchemaResponse
+			c.resource.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+			resp, err := server.UpgradeResourceState(ctx, &tfprotov6.UpgradeResourceStateRequest{
+				TypeName: c.typeName,
+				RawState: &tfprotov6.RawState{JSON: []byte(c.state)},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Diagnostics) > 0 {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics[0])
+			}
+
+			state, err := resp.UpgradedState.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var attributes map[string]tftypes.Value
+			if err := state.As(&attributes); err != nil {
+				t.Fatal(err)
+			}
+			for attribute, expected := range c.expected {
+				var actual string
+				if err := attributes[attribute].As(&actual); err != nil || actual != expected {
+					t.Errorf("expected %s to be %q, got %q", attribute, expected, actual)
+				}
+			}
+			for _, attribute := range c.null {
+				if !attributes[attribute].IsNull() {
+					t.Errorf("expected %s to be null, got %s", attribute, attributes[attribute])
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradePrivateVersion(t *testing.T) {
+	ctx := context.Background()
+
+	// Terraform keeps private state as is when upgrading, so what earlier versions wrote must keep
+	// its meaning: the version Mint should still have when the value is next written.
+	t.Run("secret written before observed versions", func(t *testing.T) {
+		private := privateState{privateVersionKey: []byte("5")}
+		model := SecretResourceModel{Vault: types.StringValue("default"), Vaults: types.SetNull(types.StringType)}
+
+		if expected := getVaultVersions(ctx, private, model)["default"].expectedVersion(); expected != 5 {
+			t.Errorf("expected the written version to be expected, got %d", expected)
+		}
+	})
+
+	t.Run("refreshed secret", func(t *testing.T) {
+		private := privateState{privateVersionKey: []byte("5"), privateObservedVersionKey: []byte("6")}
+		model := SecretResourceModel{Vault: types.StringValue("default"), Vaults: types.SetNull(types.StringType)}
+
+		if expected := getVaultVersions(ctx, private, model)["default"].expectedVersion(); expected != 6 {
+			t.Errorf("expected the observed version to be expected, got %d", expected)
+		}
+	})
+
+	t.Run("variable", func(t *testing.T) {
+		private := privateState{privateVersionKey: []byte("5")}
+
+		if expected := getPrivateInt(ctx, private, privateVersionKey); expected != 5 {
+			t.Errorf("expected version 5, got %d", expected)
+		}
+	})
+}
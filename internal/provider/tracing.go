@@ -0,0 +1,38 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a span for a single operation on a resource type, e.g. "mint_secret.Create".
+// The Mint API logging subsystem is set up along with it, once for all of the operation's requests.
+func startSpan(ctx context.Context, resourceType string, operation string) (context.Context, trace.Span) {
+	return tracing.Start(api.WithLogging(ctx), resourceType+"."+operation, tracing.ResourceTypeKey.String(resourceType))
+}
+
+// endSpan ends the span, marking it as failed when the operation reported an error diagnostic.
+func endSpan(span trace.Span, diags diag.Diagnostics) {
+	if errs := diags.Errors(); len(errs) > 0 {
+		span.SetStatus(codes.Error, errs[0].Summary())
+	}
+
+	span.End()
+}
+
+// vaultsAttribute identifies the vaults an operation touches: the vault itself, or every vault of a
+// resource written to several.
+func vaultsAttribute(vaults []string) attribute.KeyValue {
+	if len(vaults) == 1 {
+		return tracing.VaultKey.String(vaults[0])
+	}
+
+	return tracing.VaultKey.StringSlice(vaults)
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure that the function satisfies the framework interface.
+var _ function.Function = &ValidateRunDefinitionFunction{}
+
+// runDefinitionErrorAttrTypes describes each error returned by validate_run_definition.
+var runDefinitionErrorAttrTypes = map[string]attr.Type{
+	"message":   types.StringType,
+	"advice":    types.StringType,
+	"line":      types.Int64Type,
+	"column":    types.Int64Type,
+	"formatted": types.StringType,
+}
+
+func NewValidateRunDefinitionFunction() function.Function {
+	return &ValidateRunDefinitionFunction{}
+}
+
+type ValidateRunDefinitionFunction struct{}
+
+func (f *ValidateRunDefinitionFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "validate_run_definition"
+}
+
+func (f *ValidateRunDefinitionFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Validates a Mint run definition.",
+		Description: "Parses a Mint run definition locally and returns a list of the problems found, such as unknown top-level keys, " +
+			"duplicate task keys, use or after referencing undefined tasks, and malformed ${{ }} expressions. " +
+			"An empty list means no problems were found.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "yaml",
+				Description: "The contents of the run definition, e.g. file(\".mint/ci.yml\").",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{AttrTypes: runDefinitionErrorAttrTypes},
+		},
+	}
+}
+
+func (f *ValidateRunDefinitionFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var source string
+
+	resp.Error = function.ConcatFuncErrors(req.Arguments.Get(ctx, &source))
+	if resp.Error != nil {
+		return
+	}
+
+	errorMessages := rundef.Validate("<input>", []byte(source))
+
+	elements := make([]attr.Value, 0, len(errorMessages))
+	for _, errorMessage := range errorMessages {
+		var line, column int64
+		if len(errorMessage.StackTrace) > 0 {
+			line = int64(errorMessage.StackTrace[0].Line)
+			column = int64(errorMessage.StackTrace[0].Column)
+		}
+
+		element, diags := types.ObjectValue(runDefinitionErrorAttrTypes, map[string]attr.Value{
+			"message":   types.StringValue(errorMessage.Message),
+			"advice":    types.StringValue(errorMessage.Advice),
+			"line":      types.Int64Value(line),
+			"column":    types.Int64Value(column),
+			"formatted": types.StringValue(errorMessage.String()),
+		})
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.FuncErrorFromDiags(ctx, diags))
+		elements = append(elements, element)
+	}
+	if resp.Error != nil {
+		return
+	}
+
+	result, diags := types.ListValue(types.ObjectType{AttrTypes: runDefinitionErrorAttrTypes}, elements)
+	resp.Error = function.ConcatFuncErrors(resp.Error, function.FuncErrorFromDiags(ctx, diags))
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, result))
+}
@@ -0,0 +1,56 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestValidateRunDefinitionFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+output "valid" {
+  value = provider::mint::validate_run_definition(<<-YAML
+    tasks:
+      - key: build
+        run: make
+      - key: test
+        use: build
+        run: make test
+  YAML
+  )
+}
+
+output "invalid" {
+  value = provider::mint::validate_run_definition(<<-YAML
+    tasks:
+      - key: test
+        use: build
+        run: make test
+  YAML
+  )
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("valid", knownvalue.ListExact([]knownvalue.Check{})),
+					statecheck.ExpectKnownOutputValue("invalid", knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectPartial(map[string]knownvalue.Check{
+							"message": knownvalue.StringExact(`Task "build" is not defined`),
+							"line":    knownvalue.Int64Exact(3),
+							"column":  knownvalue.Int64Exact(10),
+						}),
+					})),
+				},
+			},
+		},
+	})
+}
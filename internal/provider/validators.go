@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// Ensure the validators satisfy the framework interfaces.
+var (
+	_ validator.String = base64Validator{}
+	_ validator.String = positiveDurationValidator{}
+	_ validator.String = rfc3339Validator{}
+)
+
+// base64Validator checks that a string is standard base64 which decodes to between 1 and maxBytes
+// bytes.
+type base64Validator struct {
+	maxBytes int
+}
+
+func (v base64Validator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be standard base64 encoding between 1 and %d bytes", v.maxBytes)
+}
+
+func (v base64Validator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v base64Validator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Base64",
+			fmt.Sprintf("Attribute %s %s: %s", req.Path, v.Description(ctx), err),
+		)
+		return
+	}
+
+	if len(decoded) == 0 || len(decoded) > v.maxBytes {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Secret Size",
+			fmt.Sprintf("Attribute %s %s, got: %d bytes", req.Path, v.Description(ctx), len(decoded)),
+		)
+	}
+}
+
+// positiveDurationValidator checks that a string is a positive Go duration such as "90m" or "24h".
+type positiveDurationValidator struct{}
+
+func (v positiveDurationValidator) Description(ctx context.Context) string {
+	return "value must be a positive duration such as \"90m\" or \"24h\""
+}
+
+func (v positiveDurationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v positiveDurationValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	d, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil || d <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
+
+// rfc3339Validator checks that a string is a timestamp in RFC 3339 format.
+type rfc3339Validator struct{}
+
+func (v rfc3339Validator) Description(ctx context.Context) string {
+	return "value must be a timestamp in RFC 3339 format such as \"2030-01-02T15:04:05Z\""
+}
+
+func (v rfc3339Validator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v rfc3339Validator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Timestamp",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), req.ConfigValue.ValueString()),
+		)
+	}
+}
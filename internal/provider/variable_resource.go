@@ -2,14 +2,20 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path"
 	"regexp"
+	"strings"
 
 	"github.com/rwx-research/terraform-provider-mint/internal/api"
+	"github.com/rwx-research/terraform-provider-mint/internal/rundef"
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
 
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -17,13 +23,16 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure that the resource satisfies various framework interfaces.
 var (
-	_ resource.Resource                = &VariableResource{}
-	_ resource.ResourceWithConfigure   = &VariableResource{}
-	_ resource.ResourceWithImportState = &VariableResource{}
+	_ resource.Resource                 = &VariableResource{}
+	_ resource.ResourceWithConfigure    = &VariableResource{}
+	_ resource.ResourceWithImportState  = &VariableResource{}
+	_ resource.ResourceWithModifyPlan   = &VariableResource{}
+	_ resource.ResourceWithUpgradeState = &VariableResource{}
 )
 
 func NewVariableResource() resource.Resource {
@@ -31,14 +40,84 @@ func NewVariableResource() resource.Resource {
 }
 
 type VariableResource struct {
-	client api.Client
+	client                  api.Client
+	runDefinitionsDirectory string
 }
 
 // VariableResourceModel describes the resource data model.
 type VariableResourceModel struct {
-	Vault types.String `tfsdk:"vault"`
-	Name  types.String `tfsdk:"name"`
-	Value types.String `tfsdk:"value"`
+	Vault          types.String         `tfsdk:"vault"`
+	Name           types.String         `tfsdk:"name"`
+	Value          types.String         `tfsdk:"value"`
+	ValueJSON      jsontypes.Normalized `tfsdk:"value_json"`
+	SensitiveValue types.String         `tfsdk:"sensitive_value"`
+	ID             types.String         `tfsdk:"id"`
+	CreatedAt      types.String         `tfsdk:"created_at"`
+	UpdatedAt      types.String         `tfsdk:"updated_at"`
+	UpdatedBy      types.String         `tfsdk:"updated_by"`
+}
+
+// value returns the value to write for a variable, from value, value_json or sensitive_value.
+func (m VariableResourceModel) value() string {
+	if !m.SensitiveValue.IsNull() {
+		return m.SensitiveValue.ValueString()
+	}
+	if !m.ValueJSON.IsNull() {
+		return m.ValueJSON.ValueString()
+	}
+
+	return m.Value.ValueString()
+}
+
+// setValue records a value read from Mint in whichever of value, value_json or sensitive_value the
+// variable uses. A JSON value is kept as Mint returned it, since it is compared by content rather
+// than text; if it is no longer valid JSON, it moves to value so that the next plan rewrites it.
+func (m *VariableResourceModel) setValue(value string) {
+	if !m.SensitiveValue.IsNull() {
+		m.SensitiveValue = types.StringValue(value)
+		return
+	}
+	if !m.ValueJSON.IsNull() && json.Valid([]byte(value)) {
+		m.ValueJSON = jsontypes.NewNormalizedValue(value)
+		return
+	}
+
+	m.Value, m.ValueJSON = types.StringValue(value), jsontypes.NewNormalizedNull()
+}
+
+// sensitive reports whether a variable's value must be kept out of logs and diagnostics.
+func (m VariableResourceModel) sensitive() bool {
+	return !m.SensitiveValue.IsNull()
+}
+
+// apiVariable returns the Mint variable with the given value, masked in the API logs if sensitive.
+func (m VariableResourceModel) apiVariable(value string) api.Variable {
+	return api.Variable{
+		Name:      m.Name.ValueString(),
+		Value:     value,
+		Sensitive: m.sensitive(),
+	}
+}
+
+// maskSensitiveValue returns a context in which the provider's own logs mask a sensitive value.
+// Mint still stores it as a normal variable.
+func (m VariableResourceModel) maskSensitiveValue(ctx context.Context) context.Context {
+	if !m.sensitive() || m.SensitiveValue.ValueString() == "" {
+		return ctx
+	}
+
+	ctx = tflog.MaskAllFieldValuesStrings(ctx, m.SensitiveValue.ValueString())
+	return tflog.MaskMessageStrings(ctx, m.SensitiveValue.ValueString())
+}
+
+// redactError returns the message of an error about a variable, with a sensitive value replaced so
+// that it doesn't end up in diagnostics.
+func (m VariableResourceModel) redactError(err error) string {
+	if !m.sensitive() || m.SensitiveValue.ValueString() == "" {
+		return err.Error()
+	}
+
+	return strings.ReplaceAll(err.Error(), m.SensitiveValue.ValueString(), "(sensitive value)")
 }
 
 func (r *VariableResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -47,6 +126,7 @@ func (r *VariableResource) Metadata(ctx context.Context, req resource.MetadataRe
 
 func (r *VariableResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: variableSchemaVersion,
 		Attributes: map[string]schema.Attribute{
 			"vault": schema.StringAttribute{
 				Description: "The name of a vault in Mint that should hold this variable.",
@@ -77,12 +157,44 @@ func (r *VariableResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"value": schema.StringAttribute{
-				Description: "The value of this variable.",
-				Required:    true,
+				Description: "The value of this variable, which may be empty. Exactly one of value, value_json or sensitive_value must be set.",
+				Optional:    true,
 				Validators: []validator.String{
-					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ExactlyOneOf(tfpath.MatchRoot("value_json"), tfpath.MatchRoot("sensitive_value")),
+				},
+			},
+			"value_json": schema.StringAttribute{
+				Description: "The value of this variable as a JSON document, e.g. jsonencode({...}). It must be valid JSON, and only changes to its content show in a plan: reordered keys or different whitespace, in configuration or in Mint, are not a change.",
+				Optional:    true,
+				CustomType:  jsontypes.NormalizedType{},
+			},
+			"sensitive_value": schema.StringAttribute{
+				Description: "The value of this variable, hidden from plans, logs and diagnostics. Use it for values that are not secrets but must not appear in CI logs, such as internal hostnames or account IDs. It is still stored in Mint as a normal variable.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"id": schema.StringAttribute{
+				Description: "The vault and name of the variable, as \"vault/name\".",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created_at": schema.StringAttribute{
+				Description: "When the variable was created in Mint, in RFC 3339 format.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"updated_at": schema.StringAttribute{
+				Description: "When the variable was last written in Mint, in RFC 3339 format.",
+				Computed:    true,
+			},
+			"updated_by": schema.StringAttribute{
+				Description: "Who last wrote the variable in Mint, as reported by Mint.",
+				Computed:    true,
+			},
 		},
 	}
 }
@@ -93,20 +205,29 @@ func (r *VariableResource) Configure(ctx context.Context, req resource.Configure
 		return
 	}
 
-	client, ok := req.ProviderData.(api.Client)
+	data, ok := req.ProviderData.(MintProviderData)
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected api.Client, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
+			fmt.Sprintf("Expected MintProviderData, got: %T. Please report this issue to support@rwx.com.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.runDefinitionsDirectory = data.RunDefinitionsDirectory
+}
+
+func (r *VariableResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	checkReadOnlyPlan(r.client, "variable", req, resp)
+	checkRunDefinitionReferences(ctx, r.runDefinitionsDirectory, rundef.ReferenceKindVariable, req, resp)
 }
 
 func (r *VariableResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	ctx, span := startSpan(ctx, "mint_variable", "Create")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	var err error
 	var plan VariableResourceModel
 
@@ -115,43 +236,71 @@ func (r *VariableResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
+	ctx = plan.maskSensitiveValue(ctx)
 	vault := plan.Vault.ValueString()
-	variable := api.Variable{
-		Name:  plan.Name.ValueString(),
-		Value: plan.Value.ValueString(),
-	}
+	span.SetAttributes(tracing.VaultKey.String(vault))
+	variable := plan.apiVariable(plan.value())
 
 	// Mint's backend only supports upserts to the variables. As a result, this 'create' operation
 	// could overwrite existing variables - we protect against this by explicitly checking for the
-	// existence of a variable beforehand.
-	_, err = r.client.GetVariableInVault(vault, variable)
+	// existence of a variable beforehand. Writes also ask Mint to reject them with create_only, but
+	// that isn't relied on alone.
+	_, err = r.client.GetVariableInVault(ctx, vault, plan.apiVariable(""))
 	if err == nil {
-		resp.Diagnostics.AddError(
-			"Variable already exists in Vault - please choose a different name or vault",
-			fmt.Sprintf("Vault %q already contains a variable with name %q", vault, variable.Name),
-		)
+		addVariableExistsError(&resp.Diagnostics, vault, variable.Name)
 		return
 	} else if !errors.Is(err, api.ErrNotFound) {
 		resp.Diagnostics.AddError(
 			"Error creating variable in Mint",
-			"Unexpected error: "+err.Error(),
+			"Unexpected error: "+plan.redactError(err),
 		)
 		return
 	}
 
-	_, err = r.client.SetVariableInVault(vault, variable)
+	// Each create is sent with a new idempotency key, which the client keeps across its own retries
+	// of the request, so a later create of the same variable is always written rather than replayed.
+	variable.CreateOnly = true
+
+	variable, err = r.client.SetVariableInVault(ctx, vault, variable)
 	if err != nil {
+		if errors.Is(err, api.ErrConflict) {
+			addVariableExistsError(&resp.Diagnostics, vault, plan.Name.ValueString())
+			return
+		}
+
 		resp.Diagnostics.AddError(
 			"Error creating variable in Mint",
-			"Unexpected error: "+err.Error(),
+			"Unexpected error: "+plan.redactError(err),
 		)
+
+		// Mint may have applied the create even though it failed, as when the response was lost.
+		// Terraform taints a resource whose create fails but still has state, so recording it means
+		// the next apply deletes the variable before creating it again, rather than refusing to
+		// create it because it exists.
+		plan.ID = resourceID([]string{vault}, plan.Name.ValueString())
+		plan.CreatedAt, plan.UpdatedAt, plan.UpdatedBy = types.StringNull(), types.StringNull(), types.StringNull()
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 		return
 	}
 
+	resp.Diagnostics.Append(setPrivateInt(ctx, resp.Private, privateVersionKey, variable.Version)...)
+
+	r.readTimestamps(ctx, vault, &plan, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
+// addVariableExistsError reports a create that would overwrite an existing variable.
+func addVariableExistsError(diags *diag.Diagnostics, vault string, name string) {
+	diags.AddError(
+		"Variable already exists in Vault - please choose a different name or vault",
+		fmt.Sprintf("Vault %q already contains a variable with name %q", vault, name),
+	)
+}
+
 func (r *VariableResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	ctx, span := startSpan(ctx, "mint_variable", "Read")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	var err error
 	var state VariableResourceModel
 
@@ -160,12 +309,12 @@ func (r *VariableResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
+	ctx = state.maskSensitiveValue(ctx)
 	vault := state.Vault.ValueString()
-	variable := api.Variable{
-		Name: state.Name.ValueString(),
-	}
+	span.SetAttributes(tracing.VaultKey.String(vault))
+	variable := state.apiVariable("")
 
-	variable, err = r.client.GetVariableInVault(vault, variable)
+	variable, err = r.client.GetVariableInVault(ctx, vault, variable)
 	if err != nil {
 		if errors.Is(err, api.ErrNotFound) {
 			resp.State.RemoveResource(ctx)
@@ -174,44 +323,106 @@ func (r *VariableResource) Read(ctx context.Context, req resource.ReadRequest, r
 
 		resp.Diagnostics.AddError(
 			"Error reading variable from Mint",
-			"Unexpected error: "+err.Error(),
+			"Unexpected error: "+state.redactError(err),
 		)
 		return
 	}
 
-	state.Value = types.StringValue(variable.Value)
+	// An empty value is a value like any other, but a response without one says nothing about it.
+	if !variable.ValueOmitted {
+		state.setValue(variable.Value)
+	}
+	state.ID = resourceID([]string{vault}, state.Name.ValueString())
+	state.CreatedAt, state.UpdatedAt, state.UpdatedBy = timestampValue(variable.CreatedAt), timestampValue(variable.UpdatedAt), updatedByValue(variable.UpdatedBy)
+
+	// Remember the version we saw so that a subsequent update only overwrites the variable if nobody
+	// else has changed it in the meantime.
+	resp.Diagnostics.Append(setPrivateInt(ctx, resp.Private, privateVersionKey, variable.Version)...)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *VariableResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	ctx, span := startSpan(ctx, "mint_variable", "Update")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	var err error
-	var plan VariableResourceModel
+	var plan, state VariableResourceModel
 
 	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	ctx = plan.maskSensitiveValue(ctx)
 	vault := plan.Vault.ValueString()
-	variable := api.Variable{
-		Name:  plan.Name.ValueString(),
-		Value: plan.Value.ValueString(),
+	span.SetAttributes(tracing.VaultKey.String(vault))
+
+	// A value moved between value, value_json and sensitive_value is already in Mint.
+	if plan.value() == state.value() {
+		r.readTimestamps(ctx, vault, &plan, &resp.Diagnostics)
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	variable := plan.apiVariable(plan.value())
+	variable.ExpectedVersion = getPrivateInt(ctx, req.Private, privateVersionKey)
+
+	key, diags := updateIdempotencyKey(ctx, req.Private, resp.Private, fingerprintWrite(vault, variable.Name, variable.Value))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	ctx = api.WithIdempotencyKey(ctx, key)
 
-	_, err = r.client.SetVariableInVault(vault, variable)
+	variable, err = r.client.SetVariableInVault(ctx, vault, variable)
 	if err != nil {
+		if errors.Is(err, api.ErrConflict) {
+			resp.Diagnostics.AddError(
+				"Variable was changed outside of Terraform",
+				fmt.Sprintf("Variable %q in vault %q was modified after Terraform last read it and has not been overwritten. "+
+					"Run Terraform again to refresh the variable and review the change before applying.", plan.Name.ValueString(), vault),
+			)
+			return
+		}
+
 		resp.Diagnostics.AddError(
 			"Error updating variable in Mint",
-			"Unexpected error: "+err.Error(),
+			"Unexpected error: "+plan.redactError(err),
 		)
 		return
 	}
 
+	resp.Diagnostics.Append(clearIdempotencyKey(ctx, resp.Private)...)
+	resp.Diagnostics.Append(setPrivateInt(ctx, resp.Private, privateVersionKey, variable.Version)...)
+
+	r.readTimestamps(ctx, vault, &plan, &resp.Diagnostics)
 	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
 }
 
+// readTimestamps sets a variable's id and timestamps after writing it. The variable was written
+// either way, so failing to read them back only warns.
+func (r *VariableResource) readTimestamps(ctx context.Context, vault string, model *VariableResourceModel, diags *diag.Diagnostics) {
+	model.ID = resourceID([]string{vault}, model.Name.ValueString())
+	model.CreatedAt, model.UpdatedAt, model.UpdatedBy = plannedCreatedAt(model.CreatedAt), types.StringNull(), types.StringNull()
+
+	variable, err := r.client.GetVariableInVault(ctx, vault, model.apiVariable(""))
+	if err != nil {
+		diags.AddWarning(
+			"Unable to read variable timestamps from Mint",
+			fmt.Sprintf("Variable %q in vault %q was written, but reading it back failed: %s. Its timestamps are updated on the next refresh.", model.Name.ValueString(), vault, model.redactError(err)),
+		)
+		return
+	}
+
+	model.CreatedAt, model.UpdatedAt, model.UpdatedBy = timestampValue(variable.CreatedAt), timestampValue(variable.UpdatedAt), updatedByValue(variable.UpdatedBy)
+}
+
 func (r *VariableResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	ctx, span := startSpan(ctx, "mint_variable", "Delete")
+	defer func() { endSpan(span, resp.Diagnostics) }()
+
 	var err error
 	var state VariableResourceModel
 
@@ -221,11 +432,12 @@ func (r *VariableResource) Delete(ctx context.Context, req resource.DeleteReques
 	}
 
 	vault := state.Vault.ValueString()
+	span.SetAttributes(tracing.VaultKey.String(vault))
 	variable := api.Variable{
 		Name: state.Name.ValueString(),
 	}
 
-	if err = r.client.DeleteVariableInVault(vault, variable); err != nil {
+	if err = r.client.DeleteVariableInVault(ctx, vault, variable); err != nil {
 		resp.Diagnostics.AddError(
 			"Error deleting variable in Mint",
 			"Unexpected error: "+err.Error(),
@@ -243,4 +455,9 @@ func (r *VariableResource) ImportState(ctx context.Context, req resource.ImportS
 	}
 
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tfpath.Root("name"), name)...)
+
+	// Nothing says whether the imported value may be shown, so Read records it in sensitive_value.
+	// If the configuration uses value or value_json instead, the next apply moves it there without
+	// writing it again.
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, tfpath.Root("sensitive_value"), "")...)
 }
@@ -1,8 +1,16 @@
 package provider
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"github.com/hashicorp/terraform-plugin-framework-jsontypes/jsontypes"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -23,6 +31,9 @@ resource "mint_variable" "test" {
 					resource.TestCheckResourceAttr("mint_variable.test", "vault", "terraform_provider_testing"),
 					resource.TestCheckResourceAttr("mint_variable.test", "name", "test-var"),
 					resource.TestCheckResourceAttr("mint_variable.test", "value", "foo"),
+					resource.TestCheckResourceAttr("mint_variable.test", "id", "terraform_provider_testing/test-var"),
+					resource.TestCheckResourceAttrSet("mint_variable.test", "created_at"),
+					resource.TestCheckResourceAttrSet("mint_variable.test", "updated_at"),
 				),
 			},
 			// ImportState testing
@@ -31,7 +42,9 @@ resource "mint_variable" "test" {
 				ImportState:                          true,
 				ImportStateId:                        "terraform_provider_testing/test-var",
 				ImportStateVerify:                    true,
-				ImportStateVerifyIdentifierAttribute: "value",
+				ImportStateVerifyIdentifierAttribute: "id",
+				// Imported values are kept in sensitive_value until the next apply.
+				ImportStateVerifyIgnore: []string{"value", "sensitive_value"},
 			},
 			// Update and Read testing
 			{
@@ -52,3 +65,288 @@ resource "mint_variable" "test" {
 		},
 	})
 }
+
+func TestVariableResourceEmptyValue(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-empty-var"
+  value = ""
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_variable.test", "value", ""),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "mint_variable.test",
+				ImportState:                          true,
+				ImportStateId:                        "terraform_provider_testing/test-empty-var",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "id",
+				ImportStateVerifyIgnore:              []string{"value", "sensitive_value"},
+			},
+			// Update to and from an empty value
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-empty-var"
+  value = "foo"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_variable.test", "value", "foo"),
+				),
+			},
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault = "terraform_provider_testing"
+  name  = "test-empty-var"
+  value = ""
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_variable.test", "value", ""),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestVariableResourceJSON(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault      = "terraform_provider_testing"
+  name       = "test-json-var"
+  value_json = jsonencode({ b = [1, 2], a = "x" })
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mint_variable.test", "value"),
+					resource.TestCheckResourceAttr("mint_variable.test", "value_json", `{"a":"x","b":[1,2]}`),
+				),
+			},
+			// Reformatting the document is not a change
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault      = "terraform_provider_testing"
+  name       = "test-json-var"
+  value_json = "{ \"b\": [1, 2],\n  \"a\": \"x\" }"
+}
+`,
+				PlanOnly: true,
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestVariableResourceSensitive(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault           = "terraform_provider_testing"
+  name            = "test-sensitive-var"
+  sensitive_value = "internal.example.com"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("mint_variable.test", "value"),
+					resource.TestCheckResourceAttr("mint_variable.test", "sensitive_value", "internal.example.com"),
+				),
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + `
+resource "mint_variable" "test" {
+  vault           = "terraform_provider_testing"
+  name            = "test-sensitive-var"
+  sensitive_value = "other.example.com"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("mint_variable.test", "sensitive_value", "other.example.com"),
+				),
+			},
+			// Delete testing automatically occurs in TestCase
+		},
+	})
+}
+
+func TestVariableSetValue(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		model := VariableResourceModel{Value: types.StringNull(), ValueJSON: jsontypes.NewNormalizedValue(`{"a":1,"b":2}`)}
+		model.setValue(`{ "b": 2, "a": 1 }`)
+
+		equal, diags := model.ValueJSON.StringSemanticEquals(context.Background(), jsontypes.NewNormalizedValue(`{"a":1,"b":2}`))
+		if diags.HasError() || !equal {
+			t.Errorf("expected reordered keys to be semantically equal, got %v", diags)
+		}
+		if !model.Value.IsNull() {
+			t.Errorf("expected value to stay null, got %s", model.Value)
+		}
+	})
+
+	t.Run("json no longer valid", func(t *testing.T) {
+		model := VariableResourceModel{Value: types.StringNull(), ValueJSON: jsontypes.NewNormalizedValue(`{}`)}
+		model.setValue("not json")
+
+		if model.Value.ValueString() != "not json" || !model.ValueJSON.IsNull() {
+			t.Errorf("expected the value to move to value, got %s and %s", model.Value, model.ValueJSON)
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		model := VariableResourceModel{Value: types.StringValue("foo"), ValueJSON: jsontypes.NewNormalizedNull()}
+		model.setValue("")
+
+		if model.Value.ValueString() != "" || model.Value.IsNull() || !model.ValueJSON.IsNull() {
+			t.Errorf("expected an empty value, got %s and %s", model.Value, model.ValueJSON)
+		}
+		if model.value() != "" {
+			t.Errorf("expected to write an empty value, got %q", model.value())
+		}
+	})
+}
+
+func TestVariableSensitiveValue(t *testing.T) {
+	model := VariableResourceModel{
+		Name:           types.StringValue("ACCOUNT_ID"),
+		Value:          types.StringNull(),
+		ValueJSON:      jsontypes.NewNormalizedNull(),
+		SensitiveValue: types.StringValue("123456789012"),
+	}
+
+	if variable := model.apiVariable(model.value()); variable.Value != "123456789012" || !variable.Sensitive {
+		t.Errorf("expected a sensitive variable with the sensitive value, got %+v", variable)
+	}
+
+	model.setValue("210987654321")
+	if model.SensitiveValue.ValueString() != "210987654321" || !model.Value.IsNull() {
+		t.Errorf("expected the value read to stay sensitive, got %s and %s", model.Value, model.SensitiveValue)
+	}
+
+	message := model.redactError(errors.New(`unexpected status 422: {"value":"210987654321"}`))
+	if strings.Contains(message, "210987654321") {
+		t.Errorf("expected the value to be redacted, got %q", message)
+	}
+}
+
+func TestVariableCreateChecksForExistingVariable(t *testing.T) {
+	p := newTestProvider(t)
+	p.mint.ignoreCreateOnly = true
+	p.mint.variables["default/HOSTNAME"] = api.Variable{Name: "HOSTNAME", Value: "existing", Version: 1}
+
+	_, diags := p.apply("mint_variable", testState{}, p.config("mint_variable", map[string]tftypes.Value{
+		"vault": tfString("default"),
+		"name":  tfString("HOSTNAME"),
+		"value": tfString("internal.example.com"),
+	}))
+
+	if !strings.Contains(diagnosticsString(diags), "Variable already exists in Vault") {
+		t.Errorf("expected the create to be refused, got: %s", diagnosticsString(diags))
+	}
+	if p.mint.variables["default/HOSTNAME"].Value != "existing" {
+		t.Error("expected the existing variable not to be overwritten")
+	}
+}
+
+func TestVariableImportKeepsValueSensitive(t *testing.T) {
+	p := newTestProvider(t)
+	p.mint.variables["default/HOSTNAME"] = api.Variable{Name: "HOSTNAME", Value: "internal.example.com", Version: 1}
+
+	state := p.importState("mint_variable", "default/HOSTNAME")
+
+	if !stateAttribute(state, "value").IsNull() {
+		t.Errorf("expected the imported value not to be stored in value, got %s", stateAttribute(state, "value"))
+	}
+	if !stateAttribute(state, "sensitive_value").Equal(tfString("internal.example.com")) {
+		t.Errorf("expected the imported value in sensitive_value, got %s", stateAttribute(state, "sensitive_value"))
+	}
+
+	// Configuring the value as value moves it there without writing it again.
+	state, diags := p.apply("mint_variable", state, p.config("mint_variable", map[string]tftypes.Value{
+		"vault": tfString("default"),
+		"name":  tfString("HOSTNAME"),
+		"value": tfString("internal.example.com"),
+	}))
+	p.requireNoErrors(diags)
+
+	if !stateAttribute(state, "value").Equal(tfString("internal.example.com")) || !stateAttribute(state, "sensitive_value").IsNull() {
+		t.Errorf("expected the value to move to value, got %s", state.value)
+	}
+	if version := p.mint.variables["default/HOSTNAME"].Version; version != 1 {
+		t.Errorf("expected the variable not to be written again, got version %d", version)
+	}
+}
+
+func TestVariableCreateWithLostResponse(t *testing.T) {
+	p := newTestProvider(t)
+	config := p.config("mint_variable", map[string]tftypes.Value{
+		"vault": tfString("default"),
+		"name":  tfString("HOSTNAME"),
+		"value": tfString("internal.example.com"),
+	})
+
+	p.mint.loseWriteResponses = true
+	state, diags := p.apply("mint_variable", testState{}, config)
+	if !hasErrors(diags) {
+		t.Fatal("expected the create to fail")
+	}
+	if state.value.IsNull() {
+		t.Fatal("expected the variable Mint may have created to be recorded")
+	}
+
+	// Terraform replaces the tainted variable on the next apply, deleting what Mint created rather
+	// than refusing to create a variable that already exists.
+	p.mint.loseWriteResponses = false
+	p.requireNoErrors(p.destroy("mint_variable", state))
+	_, diags = p.apply("mint_variable", testState{}, config)
+	p.requireNoErrors(diags)
+
+	if p.mint.variables["default/HOSTNAME"].Value != "internal.example.com" {
+		t.Error("expected the variable to be written to Mint")
+	}
+}
+
+func TestVariableUpdateKeepsCreatedAtWhenReadBackFails(t *testing.T) {
+	p := newTestProvider(t)
+	configWith := func(value string) tftypes.Value {
+		return p.config("mint_variable", map[string]tftypes.Value{
+			"vault": tfString("default"),
+			"name":  tfString("HOSTNAME"),
+			"value": tfString(value),
+		})
+	}
+
+	state, diags := p.apply("mint_variable", testState{}, configWith("internal.example.com"))
+	p.requireNoErrors(diags)
+	createdAt := stateAttribute(state, "created_at")
+
+	p.mint.failReads["default"] = true
+	state, diags = p.apply("mint_variable", state, configWith("external.example.com"))
+	p.requireNoErrors(diags)
+
+	if !stateAttribute(state, "created_at").Equal(createdAt) {
+		t.Errorf("expected created_at to be kept, got %s", stateAttribute(state, "created_at"))
+	}
+}
@@ -0,0 +1,150 @@
+package rundef
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReferenceKind identifies what a run definition refers to in a vault.
+type ReferenceKind string
+
+const (
+	ReferenceKindVault     ReferenceKind = "vault"
+	ReferenceKindSecret    ReferenceKind = "secret"
+	ReferenceKindVariable  ReferenceKind = "variable"
+	ReferenceKindOIDCToken ReferenceKind = "oidc_token"
+)
+
+// Reference is a single use of a vault, or of a secret, variable or OIDC token within one, by a
+// run definition. Name is empty for references to a vault as a whole.
+type Reference struct {
+	Kind     ReferenceKind
+	Vault    string
+	Name     string
+	FileName string
+	Line     int
+}
+
+// vaultReferencePattern matches vaults.<vault>, optionally followed by .secrets.<name>,
+// .vars.<name> or .oidc.<name>, inside an expression.
+var vaultReferencePattern = regexp.MustCompile(`\bvaults\.([a-zA-Z0-9_-]+)(?:\.(secrets|vars|oidc)\.([a-zA-Z0-9_-]+))?`)
+
+var referenceKinds = map[string]ReferenceKind{
+	"secrets": ReferenceKindSecret,
+	"vars":    ReferenceKindVariable,
+	"oidc":    ReferenceKindOIDCToken,
+}
+
+// ScanDirectory returns the references made by every .yml and .yaml file in directory and its
+// subdirectories, in file order.
+func ScanDirectory(directory string) ([]Reference, error) {
+	var references []Reference
+
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		extension := filepath.Ext(path)
+		if extension != ".yml" && extension != ".yaml" {
+			return nil
+		}
+
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		fileReferences, err := References(path, source)
+		if err != nil {
+			return err
+		}
+
+		references = append(references, fileReferences...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return references, nil
+}
+
+// References returns every vault, secret, variable and OIDC token referenced by a run definition,
+// in the order they appear. Secrets, variables and OIDC tokens are found in ${{ }} expressions;
+// vaults are also found in the top-level tool-cache.
+func References(fileName string, source []byte) ([]Reference, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", fileName, err)
+	}
+
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	var references []Reference
+	root := document.Content[0]
+
+	if toolCache := mappingValue(root, "tool-cache"); toolCache != nil && toolCache.Kind == yaml.MappingNode {
+		if vault := mappingValue(toolCache, "vault"); vault != nil && vault.Kind == yaml.ScalarNode && vault.Value != "" {
+			references = append(references, Reference{Kind: ReferenceKindVault, Vault: vault.Value, FileName: fileName, Line: vault.Line})
+		}
+	}
+
+	return append(references, expressionReferences(fileName, root)...), nil
+}
+
+func expressionReferences(fileName string, node *yaml.Node) []Reference {
+	if node.Kind != yaml.ScalarNode {
+		var references []Reference
+		for _, child := range node.Content {
+			references = append(references, expressionReferences(fileName, child)...)
+		}
+
+		return references
+	}
+
+	// Block scalars start on the line after their indicator.
+	firstLine := node.Line
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		firstLine++
+	}
+
+	var references []Reference
+	offset := 0
+	for {
+		start := strings.Index(node.Value[offset:], "${{")
+		if start < 0 {
+			return references
+		}
+		start += offset
+
+		end := strings.Index(node.Value[start:], "}}")
+		if end < 0 {
+			return references
+		}
+		end += start
+		offset = end + 2
+
+		line := firstLine + strings.Count(node.Value[:start], "\n")
+		for _, match := range vaultReferencePattern.FindAllStringSubmatch(node.Value[start:end], -1) {
+			reference := Reference{Kind: ReferenceKindVault, Vault: match[1], FileName: fileName, Line: line}
+			if match[2] != "" {
+				reference.Kind = referenceKinds[match[2]]
+				reference.Name = match[3]
+			}
+
+			references = append(references, reference)
+		}
+	}
+}
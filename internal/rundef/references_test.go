@@ -0,0 +1,59 @@
+package rundef
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanDirectoryFindsThisRepositorysReferences(t *testing.T) {
+	references, err := ScanDirectory("../../.mint")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileName := "../../.mint/continuous_deployment.yml"
+	expected := []Reference{
+		{Kind: ReferenceKindVault, Vault: "terraform_provider", FileName: fileName, Line: 2},
+		{Kind: ReferenceKindSecret, Vault: "terraform_provider", Name: "RWX_ACCESS_TOKEN", FileName: fileName, Line: 112},
+		{Kind: ReferenceKindSecret, Vault: "terraform_provider", Name: "GPG_PRIVATE_KEY", FileName: fileName, Line: 118},
+		{Kind: ReferenceKindVault, Vault: "default", FileName: fileName, Line: 128},
+		{Kind: ReferenceKindSecret, Vault: "terraform_provider", Name: "GPG_PASSPHRASE", FileName: fileName, Line: 130},
+	}
+
+	if !reflect.DeepEqual(references, expected) {
+		t.Errorf("expected %+v, got %+v", expected, references)
+	}
+}
+
+func TestReferences(t *testing.T) {
+	source := `tasks:
+  - key: deploy
+    run: |
+      echo "vaults.ignored.secrets.NOT_AN_EXPRESSION"
+      deploy --token ${{ vaults.production.secrets.DEPLOY_TOKEN }}
+    env:
+      REGION: ${{ vaults.production.vars.REGION }}
+      AWS_TOKEN: ${{ vaults.production.oidc.aws }}
+`
+
+	references, err := References("mint.yml", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Reference{
+		{Kind: ReferenceKindSecret, Vault: "production", Name: "DEPLOY_TOKEN", FileName: "mint.yml", Line: 5},
+		{Kind: ReferenceKindVariable, Vault: "production", Name: "REGION", FileName: "mint.yml", Line: 7},
+		{Kind: ReferenceKindOIDCToken, Vault: "production", Name: "aws", FileName: "mint.yml", Line: 8},
+	}
+
+	if !reflect.DeepEqual(references, expected) {
+		t.Errorf("expected %+v, got %+v", expected, references)
+	}
+}
+
+func TestReferencesReportsUnparseableYAML(t *testing.T) {
+	if _, err := References("mint.yml", []byte("on: [\n")); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,256 @@
+package rundef
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Definition is a run definition built up from typed values rather than written as YAML.
+type Definition struct {
+	ToolCache        *ToolCache
+	On               *Triggers
+	ConcurrencyPools []ConcurrencyPool
+	Base             *Base
+	Tasks            []Task
+}
+
+type ToolCache struct {
+	Vault string
+}
+
+type Triggers struct {
+	GitHubPush        []GitHubTrigger
+	GitHubPullRequest []GitHubTrigger
+	Cron              []CronTrigger
+}
+
+type GitHubTrigger struct {
+	If     string
+	Init   map[string]string
+	Target []string
+}
+
+type CronTrigger struct {
+	Key      string
+	Schedule string
+	If       string
+	Init     map[string]string
+	Target   []string
+}
+
+type ConcurrencyPool struct {
+	ID         string
+	Capacity   int64
+	OnOverflow string
+}
+
+type Base struct {
+	OS   string
+	Tag  string
+	Arch string
+}
+
+type Task struct {
+	Key       string
+	Call      string
+	Use       []string
+	After     []string
+	If        string
+	Run       string
+	With      map[string]string
+	Filter    []string
+	Env       map[string]string
+	ToolCache string
+}
+
+// SecretExpression returns the expression a run definition uses to read a secret from a vault.
+func SecretExpression(vault string, name string) string {
+	return fmt.Sprintf("${{ vaults.%s.secrets.%s }}", vault, name)
+}
+
+// VariableExpression returns the expression a run definition uses to read a variable from a vault.
+func VariableExpression(vault string, name string) string {
+	return fmt.Sprintf("${{ vaults.%s.vars.%s }}", vault, name)
+}
+
+// Render returns the canonical YAML for a run definition. Keys are written in a fixed order and
+// maps are sorted, so the same definition always renders to the same bytes.
+func Render(definition Definition) ([]byte, error) {
+	root := mapping()
+
+	if definition.ToolCache != nil {
+		root.add("tool-cache", mapping().add("vault", scalar(definition.ToolCache.Vault)).node())
+	}
+
+	if definition.On != nil {
+		on := mapping()
+
+		github := mapping()
+		if len(definition.On.GitHubPush) > 0 {
+			github.add("push", githubTriggers(definition.On.GitHubPush))
+		}
+		if len(definition.On.GitHubPullRequest) > 0 {
+			github.add("pull_request", githubTriggers(definition.On.GitHubPullRequest))
+		}
+		if len(github.Content) > 0 {
+			on.add("github", github.node())
+		}
+
+		if len(definition.On.Cron) > 0 {
+			cron := sequence()
+			for _, trigger := range definition.On.Cron {
+				cron.Content = append(cron.Content, mapping().
+					add("key", scalar(trigger.Key)).
+					add("schedule", scalar(trigger.Schedule)).
+					addOptional("if", trigger.If).
+					addMap("init", trigger.Init).
+					addList("target", trigger.Target).
+					node())
+			}
+			on.add("cron", cron)
+		}
+
+		root.add("on", on.node())
+	}
+
+	if len(definition.ConcurrencyPools) > 0 {
+		pools := sequence()
+		for _, pool := range definition.ConcurrencyPools {
+			pools.Content = append(pools.Content, mapping().
+				add("id", scalar(pool.ID)).
+				add("capacity", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.FormatInt(pool.Capacity, 10)}).
+				addOptional("on-overflow", pool.OnOverflow).
+				node())
+		}
+		root.add("concurrency-pools", pools)
+	}
+
+	if definition.Base != nil {
+		root.add("base", mapping().
+			add("os", scalar(definition.Base.OS)).
+			add("tag", scalar(definition.Base.Tag)).
+			addOptional("arch", definition.Base.Arch).
+			node())
+	}
+
+	tasks := sequence()
+	for _, task := range definition.Tasks {
+		tasks.Content = append(tasks.Content, mapping().
+			add("key", scalar(task.Key)).
+			addOptional("call", task.Call).
+			addList("use", task.Use).
+			addList("after", task.After).
+			addOptional("if", task.If).
+			addOptional("run", task.Run).
+			addMap("with", task.With).
+			addList("filter", task.Filter).
+			addMap("env", task.Env).
+			addOptional("tool-cache", task.ToolCache).
+			node())
+	}
+	root.add("tasks", tasks)
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root.node()); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func githubTriggers(triggers []GitHubTrigger) *yaml.Node {
+	nodes := sequence()
+	for _, trigger := range triggers {
+		nodes.Content = append(nodes.Content, mapping().
+			addOptional("if", trigger.If).
+			addMap("init", trigger.Init).
+			addList("target", trigger.Target).
+			node())
+	}
+
+	return nodes
+}
+
+// mappingBuilder builds a YAML mapping node, keeping keys in the order they are added.
+type mappingBuilder yaml.Node
+
+func mapping() *mappingBuilder {
+	return &mappingBuilder{Kind: yaml.MappingNode}
+}
+
+func (m *mappingBuilder) node() *yaml.Node {
+	return (*yaml.Node)(m)
+}
+
+func (m *mappingBuilder) add(key string, value *yaml.Node) *mappingBuilder {
+	m.Content = append(m.Content, scalar(key), value)
+	return m
+}
+
+func (m *mappingBuilder) addOptional(key string, value string) *mappingBuilder {
+	if value == "" {
+		return m
+	}
+
+	return m.add(key, scalar(value))
+}
+
+func (m *mappingBuilder) addList(key string, values []string) *mappingBuilder {
+	if len(values) == 0 {
+		return m
+	}
+
+	list := sequence()
+	for _, value := range values {
+		list.Content = append(list.Content, scalar(value))
+	}
+	if len(values) <= 3 {
+		list.Style = yaml.FlowStyle
+	}
+
+	return m.add(key, list)
+}
+
+func (m *mappingBuilder) addMap(key string, values map[string]string) *mappingBuilder {
+	if len(values) == 0 {
+		return m
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	nested := mapping()
+	for _, k := range keys {
+		nested.add(k, scalar(values[k]))
+	}
+
+	return m.add(key, nested.node())
+}
+
+func sequence() *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode}
+}
+
+// scalar returns a string node. Multi-line strings are written as literal blocks so that scripts
+// stay readable, and everything else is left for the encoder to quote as needed.
+func scalar(value string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if strings.Contains(strings.TrimSuffix(value, "\n"), "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+
+	return node
+}
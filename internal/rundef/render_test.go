@@ -0,0 +1,72 @@
+package rundef
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	rendered, err := Render(Definition{
+		ToolCache: &ToolCache{Vault: "terraform_provider"},
+		On: &Triggers{
+			GitHubPush: []GitHubTrigger{
+				{If: `${{ event.git.tag == "" }}`, Init: map[string]string{"release": "false", "ref": "${{ event.git.ref }}"}},
+			},
+			Cron: []CronTrigger{
+				{Key: "nightly", Schedule: "0 7 * * *", Target: []string{"test"}},
+			},
+		},
+		ConcurrencyPools: []ConcurrencyPool{
+			{ID: "cd-${{ init.ref }}", Capacity: 1, OnOverflow: "cancel-running"},
+		},
+		Base: &Base{OS: "ubuntu 24.04", Tag: "1.2"},
+		Tasks: []Task{
+			{Key: "code", Call: "git/clone 1.8.0", With: map[string]string{"ref": "${{ init.ref }}", "preserve-git-dir": "true"}},
+			{Key: "test", Use: []string{"code"}, Run: "go mod download\ngo test ./...\n", Env: map[string]string{"RWX_ACCESS_TOKEN": SecretExpression("terraform_provider", "RWX_ACCESS_TOKEN")}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `tool-cache:
+  vault: terraform_provider
+on:
+  github:
+    push:
+      - if: ${{ event.git.tag == "" }}
+        init:
+          ref: ${{ event.git.ref }}
+          release: "false"
+  cron:
+    - key: nightly
+      schedule: 0 7 * * *
+      target: [test]
+concurrency-pools:
+  - id: cd-${{ init.ref }}
+    capacity: 1
+    on-overflow: cancel-running
+base:
+  os: ubuntu 24.04
+  tag: "1.2"
+tasks:
+  - key: code
+    call: git/clone 1.8.0
+    with:
+      preserve-git-dir: "true"
+      ref: ${{ init.ref }}
+  - key: test
+    use: [code]
+    run: |
+      go mod download
+      go test ./...
+    env:
+      RWX_ACCESS_TOKEN: ${{ vaults.terraform_provider.secrets.RWX_ACCESS_TOKEN }}
+`
+	if string(rendered) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+
+	if errs := Validate("mint.yml", rendered); len(errs) > 0 {
+		t.Errorf("expected the rendered run definition to be valid, got %v", errs)
+	}
+}
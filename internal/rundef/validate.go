@@ -0,0 +1,272 @@
+// Package rundef inspects Mint run definitions locally, without calling the Mint API.
+package rundef
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rwx-research/terraform-provider-mint/internal/api"
+
+	"gopkg.in/yaml.v3"
+)
+
+// topLevelKeys are the keys Mint accepts at the root of a run definition.
+var topLevelKeys = map[string]bool{
+	"aliases":           true,
+	"base":              true,
+	"concurrency-pools": true,
+	"on":                true,
+	"tasks":             true,
+	"tool-cache":        true,
+}
+
+var yamlErrorLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// Validate checks a run definition and returns one error message per problem found. The messages
+// use the same shape as errors returned by the Mint API, so they can be rendered the same way.
+func Validate(fileName string, source []byte) []api.ErrorMessage {
+	v := validator{
+		fileName: fileName,
+		lines:    strings.Split(string(source), "\n"),
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		line, message := 0, err.Error()
+		if match := yamlErrorLinePattern.FindStringSubmatch(message); match != nil {
+			line, _ = strconv.Atoi(match[1])
+			message = match[2]
+		}
+
+		v.add(line, 1, "Unable to parse YAML: "+message, "")
+		return v.errors
+	}
+
+	if len(document.Content) == 0 {
+		v.add(0, 0, "The run definition is empty", "A run definition must contain at least a tasks key.")
+		return v.errors
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		v.addAt(root, "The run definition must be a mapping", "")
+		return v.errors
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		if !topLevelKeys[key.Value] {
+			v.addAt(key, fmt.Sprintf("Unknown top-level key %q", key.Value), "Valid top-level keys are: "+strings.Join(sortedKeys(topLevelKeys), ", ")+".")
+		}
+
+		if key.Value == "tasks" {
+			v.validateTasks(value)
+		}
+	}
+
+	v.validateExpressions(root)
+
+	return v.errors
+}
+
+type validator struct {
+	fileName string
+	lines    []string
+	errors   []api.ErrorMessage
+}
+
+func (v *validator) addAt(node *yaml.Node, message string, advice string) {
+	v.add(node.Line, node.Column, message, advice)
+}
+
+func (v *validator) add(line int, column int, message string, advice string) {
+	errorMessage := api.ErrorMessage{
+		Message: message,
+		Advice:  advice,
+	}
+
+	if line > 0 {
+		errorMessage.Frame = v.frame(line, column)
+		errorMessage.StackTrace = []api.StackEntry{{FileName: v.fileName, Line: line, Column: column}}
+	}
+
+	v.errors = append(v.errors, errorMessage)
+}
+
+// frame renders the offending source line with a caret under the given column.
+func (v *validator) frame(line int, column int) string {
+	if line > len(v.lines) {
+		return ""
+	}
+
+	gutter := fmt.Sprintf("%d | ", line)
+	return gutter + v.lines[line-1] + "\n" +
+		strings.Repeat(" ", len(gutter)-2) + "| " + strings.Repeat(" ", max(column-1, 0)) + "^"
+}
+
+func (v *validator) validateTasks(tasks *yaml.Node) {
+	if tasks.Kind != yaml.SequenceNode {
+		v.addAt(tasks, "tasks must be a list", "")
+		return
+	}
+
+	defined := map[string]*yaml.Node{}
+	var references []*yaml.Node
+
+	for _, task := range tasks.Content {
+		if task.Kind != yaml.MappingNode {
+			v.addAt(task, "Each task must be a mapping", "")
+			continue
+		}
+
+		key := mappingValue(task, "key")
+		switch {
+		case key == nil:
+			v.addAt(task, "Task is missing a key", "Every task needs a unique key so that other tasks can use it.")
+		case key.Kind != yaml.ScalarNode || key.Value == "":
+			v.addAt(key, "Task key must be a non-empty string", "")
+		case defined[key.Value] != nil:
+			v.addAt(key, fmt.Sprintf("Duplicate task key %q", key.Value), fmt.Sprintf("The key is first defined on line %d. Task keys must be unique within a run definition.", defined[key.Value].Line))
+		default:
+			defined[key.Value] = key
+		}
+
+		for _, field := range []string{"use", "after"} {
+			references = append(references, taskReferences(mappingValue(task, field))...)
+		}
+	}
+
+	for _, reference := range references {
+		if defined[reference.Value] == nil {
+			v.addAt(reference, fmt.Sprintf("Task %q is not defined", reference.Value), "use and after must reference the key of a task defined in this run definition.")
+		}
+	}
+}
+
+// validateExpressions checks every ${{ }} expression in the document for syntax errors.
+func (v *validator) validateExpressions(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		for _, problem := range expressionProblems(node.Value) {
+			v.addAt(node, problem, "Expressions are written as ${{ expression }}.")
+		}
+		return
+	}
+
+	for _, child := range node.Content {
+		v.validateExpressions(child)
+	}
+}
+
+// expressionProblems returns a description of each malformed ${{ }} expression in value.
+func expressionProblems(value string) []string {
+	var problems []string
+
+	rest := value
+	for {
+		start := strings.Index(rest, "${{")
+		if start < 0 {
+			return problems
+		}
+		rest = rest[start+3:]
+
+		end := strings.Index(rest, "}}")
+		if end < 0 {
+			return append(problems, "Unterminated expression: missing closing }}")
+		}
+
+		body := rest[:end]
+		rest = rest[end+2:]
+
+		switch {
+		case strings.Contains(body, "${{"):
+			problems = append(problems, "Expressions cannot be nested")
+		case strings.TrimSpace(body) == "":
+			problems = append(problems, "Empty expression")
+		default:
+			if problem := unbalanced(body); problem != "" {
+				problems = append(problems, fmt.Sprintf("Invalid expression %q: %s", strings.TrimSpace(body), problem))
+			}
+		}
+	}
+}
+
+// unbalanced reports unterminated string literals and mismatched brackets in an expression body.
+func unbalanced(body string) string {
+	pairs := map[rune]rune{')': '(', ']': '['}
+	var stack []rune
+	var quote rune
+
+	for _, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(' || r == '[':
+			stack = append(stack, r)
+		case r == ')' || r == ']':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[r] {
+				return fmt.Sprintf("unexpected %q", r)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if quote != 0 {
+		return "unterminated string literal"
+	}
+	if len(stack) > 0 {
+		return fmt.Sprintf("unclosed %q", stack[len(stack)-1])
+	}
+
+	return ""
+}
+
+// taskReferences returns the task keys named by a use or after value, which may be a single key
+// or a list of keys. Values computed by expressions can't be checked locally and are skipped.
+func taskReferences(node *yaml.Node) []*yaml.Node {
+	if node == nil {
+		return nil
+	}
+
+	candidates := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		candidates = node.Content
+	}
+
+	var references []*yaml.Node
+	for _, candidate := range candidates {
+		if candidate.Kind == yaml.ScalarNode && candidate.Value != "" && !strings.Contains(candidate.Value, "${{") {
+			references = append(references, candidate)
+		}
+	}
+
+	return references
+}
+
+// mappingValue returns the value for key in a mapping node, or nil if the key is absent.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
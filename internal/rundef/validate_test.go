@@ -0,0 +1,88 @@
+package rundef
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateAcceptsThisRepositorysRunDefinition(t *testing.T) {
+	source, err := os.ReadFile("../../.mint/continuous_deployment.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := Validate("continuous_deployment.yml", source); len(errs) > 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected []string
+		line     int
+	}{
+		{
+			name:     "unparseable YAML",
+			source:   "on: [\ntasks: []\n",
+			expected: []string{"Unable to parse YAML"},
+			line:     2,
+		},
+		{
+			name:     "unknown top-level key",
+			source:   "task:\n  - key: a\n",
+			expected: []string{`Unknown top-level key "task"`},
+			line:     1,
+		},
+		{
+			name:     "duplicate task keys",
+			source:   "tasks:\n  - key: a\n    run: echo\n  - key: a\n    run: echo\n",
+			expected: []string{`Duplicate task key "a"`},
+			line:     4,
+		},
+		{
+			name:     "use of an undefined task",
+			source:   "tasks:\n  - key: a\n    run: echo\n  - key: b\n    use: [a, c]\n",
+			expected: []string{`Task "c" is not defined`},
+			line:     5,
+		},
+		{
+			name:     "unterminated expression",
+			source:   "tasks:\n  - key: a\n    run: echo ${{ init.ref\n",
+			expected: []string{"Unterminated expression"},
+			line:     3,
+		},
+		{
+			name:     "empty expression",
+			source:   "tasks:\n  - key: a\n    run: echo ${{ }}\n",
+			expected: []string{"Empty expression"},
+			line:     3,
+		},
+		{
+			name:     "unbalanced expression",
+			source:   "tasks:\n  - key: a\n    if: ${{ (init.release }}\n",
+			expected: []string{`Invalid expression "(init.release": unclosed '('`},
+			line:     3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := Validate("mint.yml", []byte(c.source))
+			if len(errs) != len(c.expected) {
+				t.Fatalf("expected %d errors, got %v", len(c.expected), errs)
+			}
+
+			for i, expected := range c.expected {
+				if !strings.HasPrefix(errs[i].Message, expected) {
+					t.Errorf("expected error %q, got %q", expected, errs[i].Message)
+				}
+				if len(errs[i].StackTrace) != 1 || errs[i].StackTrace[0].Line != c.line {
+					t.Errorf("expected error on line %d, got %v", c.line, errs[i].StackTrace)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+// Package sops decrypts values from SOPS-encrypted documents and age-armored files using age
+// identities, so that plaintext only ever exists in memory.
+package sops
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// AgeKeyEnv holds age identities directly, one per line.
+	AgeKeyEnv = "SOPS_AGE_KEY"
+	// AgeKeyFileEnv points to a file of age identities.
+	AgeKeyFileEnv = "SOPS_AGE_KEY_FILE"
+
+	// ageKeyUserConfigPath is where sops looks for age identities when neither environment variable
+	// is set, relative to the user's config directory.
+	ageKeyUserConfigPath = "sops/age/keys.txt"
+)
+
+// encryptedValue matches a value encrypted by sops.
+var encryptedValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// Identities loads the age identities sops would use: those in SOPS_AGE_KEY and SOPS_AGE_KEY_FILE,
+// or sops/age/keys.txt in the user's config directory when neither is set.
+func Identities() ([]age.Identity, error) {
+	var identities []age.Identity
+
+	if keys, ok := os.LookupEnv(AgeKeyEnv); ok {
+		parsed, err := age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", AgeKeyEnv, err)
+		}
+		identities = append(identities, parsed...)
+	}
+
+	keyFile, ok := os.LookupEnv(AgeKeyFileEnv)
+	if !ok && len(identities) == 0 {
+		configDir, err := os.UserConfigDir()
+		if err == nil {
+			keyFile = filepath.Join(configDir, ageKeyUserConfigPath)
+		}
+		if _, err := os.Stat(keyFile); keyFile == "" || err != nil {
+			return nil, fmt.Errorf("no age identities found: set %s or %s", AgeKeyFileEnv, AgeKeyEnv)
+		}
+	}
+
+	if keyFile != "" {
+		source, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read age identities: %w", err)
+		}
+
+		parsed, err := age.ParseIdentities(bytes.NewReader(source))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse age identities in %s: %w", keyFile, err)
+		}
+		identities = append(identities, parsed...)
+	}
+
+	return identities, nil
+}
+
+// DecryptArmored decrypts an ASCII-armored age file, as written by age -a.
+func DecryptArmored(source string, identities []age.Identity) ([]byte, error) {
+	reader, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(source)+"\n")), identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+// Decrypt returns the plaintext of one value in a SOPS-encrypted YAML or JSON document. The key is
+// the path to the value, with the keys of nested mappings separated by dots, such as
+// "registry.password". Only the data key encrypted for age recipients is used, and only the
+// requested value is decrypted; it is authenticated against its path, but the document's MAC is not
+// checked. A value in plaintext is only accepted where the document's sops settings leave it
+// unencrypted, so that an encrypted value can't be swapped for one in plaintext.
+func Decrypt(source []byte, key string, identities []age.Identity) ([]byte, error) {
+	var document map[string]any
+	if err := yaml.Unmarshal(source, &document); err != nil {
+		return nil, fmt.Errorf("unable to parse document: %w", err)
+	}
+
+	var metadata documentMetadata
+	if err := remarshal(document["sops"], &metadata); err != nil || document["sops"] == nil {
+		return nil, errors.New("document is not encrypted with sops: it has no sops metadata")
+	}
+	if len(metadata.Age) == 0 {
+		return nil, errors.New("document has no data key encrypted for an age recipient")
+	}
+
+	path := strings.Split(key, ".")
+	if path[0] == "sops" {
+		return nil, fmt.Errorf("key %q refers to the sops metadata", key)
+	}
+
+	var value any = document
+	for i, segment := range path {
+		mapping, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("key %q not found: %s is not a mapping", key, strings.Join(path[:i], "."))
+		}
+		if value, ok = mapping[segment]; !ok {
+			return nil, fmt.Errorf("key %q not found", key)
+		}
+	}
+
+	var matches []string
+	switch value := value.(type) {
+	case string:
+		matches = encryptedValue.FindStringSubmatch(value)
+	case map[string]any, []any, nil:
+		return nil, fmt.Errorf("key %q does not refer to a single value", key)
+	}
+
+	if matches == nil {
+		encrypted, err := metadata.encrypts(path)
+		if err != nil {
+			return nil, err
+		}
+		if encrypted {
+			return nil, fmt.Errorf("key %q is not encrypted, but the document's sops settings require it to be", key)
+		}
+
+		// Values sops leaves unencrypted are read as they were written.
+		return []byte(fmt.Sprint(value)), nil
+	}
+
+	var dataKey []byte
+	var errs []error
+	for _, recipient := range metadata.Age {
+		decrypted, err := DecryptArmored(recipient.Enc, identities)
+		if err == nil {
+			dataKey = decrypted
+			break
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", recipient.Recipient, err))
+	}
+	if dataKey == nil {
+		return nil, fmt.Errorf("unable to decrypt the data key with the available age identities: %w", errors.Join(errs...))
+	}
+
+	return decryptValue(matches, dataKey, strings.Join(path, ":")+":")
+}
+
+// documentMetadata is the part of a document's sops metadata used to decrypt a value.
+type documentMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+
+	UnencryptedSuffix string `yaml:"unencrypted_suffix"`
+	EncryptedSuffix   string `yaml:"encrypted_suffix"`
+	UnencryptedRegex  string `yaml:"unencrypted_regex"`
+	EncryptedRegex    string `yaml:"encrypted_regex"`
+}
+
+// encrypts reports whether sops encrypts the value at a path, applying the settings in the same
+// order sops does. A setting matches a value if it matches any key along its path.
+func (m documentMetadata) encrypts(path []string) (bool, error) {
+	encrypted := true
+
+	if m.UnencryptedSuffix != "" && anyKey(path, func(key string) bool { return strings.HasSuffix(key, m.UnencryptedSuffix) }) {
+		encrypted = false
+	}
+	if m.EncryptedSuffix != "" {
+		encrypted = anyKey(path, func(key string) bool { return strings.HasSuffix(key, m.EncryptedSuffix) })
+	}
+	if m.UnencryptedRegex != "" {
+		pattern, err := regexp.Compile(m.UnencryptedRegex)
+		if err != nil {
+			return false, fmt.Errorf("invalid unencrypted_regex in sops metadata: %w", err)
+		}
+		if anyKey(path, pattern.MatchString) {
+			encrypted = false
+		}
+	}
+	if m.EncryptedRegex != "" {
+		pattern, err := regexp.Compile(m.EncryptedRegex)
+		if err != nil {
+			return false, fmt.Errorf("invalid encrypted_regex in sops metadata: %w", err)
+		}
+		encrypted = anyKey(path, pattern.MatchString)
+	}
+
+	return encrypted, nil
+}
+
+// anyKey reports whether any key along a path matches.
+func anyKey(path []string, match func(string) bool) bool {
+	for _, key := range path {
+		if match(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decryptValue decrypts the parts of an encrypted value with a document's data key. Sops
+// authenticates each value with its path as additional data.
+func decryptValue(matches []string, dataKey []byte, additionalData string) ([]byte, error) {
+	var parts [3][]byte
+	for i := range parts {
+		decoded, err := base64.StdEncoding.DecodeString(matches[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid encrypted value: %w", err)
+		}
+		parts[i] = decoded
+	}
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt value: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// remarshal decodes a generic value into a typed one.
+func remarshal(in any, out any) error {
+	encoded, err := yaml.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(encoded, out)
+}
@@ -0,0 +1,179 @@
+package sops
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecrypt(t *testing.T) {
+	t.Setenv(AgeKeyFileEnv, "testdata/keys.txt")
+
+	identities, err := Identities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		file     string
+		key      string
+		expected string
+		err      string
+	}{
+		{file: "secrets.enc.yaml", key: "api_token", expected: "tok_123"},
+		{file: "secrets.enc.yaml", key: "registry.password", expected: "hunter2"},
+		{file: "secrets.enc.yaml", key: "registry.port", expected: "5000"},
+		{file: "secrets.enc.json", key: "registry.password", expected: "hunter2"},
+		{file: "secrets.enc.yaml", key: "registry", err: "does not refer to a single value"},
+		{file: "secrets.enc.yaml", key: "registry.username", err: "not found"},
+		{file: "secrets.enc.yaml", key: "api_token.value", err: "api_token is not a mapping"},
+		{file: "secrets.enc.yaml", key: "sops.mac", err: "refers to the sops metadata"},
+		{file: "keys.txt", key: "api_token", err: "unable to parse document"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.file+"/"+c.key, func(t *testing.T) {
+			source, err := os.ReadFile("testdata/" + c.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			plaintext, err := Decrypt(source, c.key, identities)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(plaintext) != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, plaintext)
+			}
+		})
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	t.Setenv(AgeKeyFileEnv, "testdata/keys.txt")
+
+	identities, err := Identities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.ReadFile("testdata/secrets.enc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving an encrypted value to another key must not decrypt, since values are authenticated
+	// against their path.
+	lines := strings.Split(string(source), "\n")
+	password := strings.TrimPrefix(lines[2], "    password: ")
+	tampered := strings.Replace(string(source), lines[0], "api_token: "+password, 1)
+
+	if _, err := Decrypt([]byte(tampered), "api_token", identities); err == nil {
+		t.Fatal("expected an error decrypting a moved value")
+	}
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	if _, err := Decrypt([]byte("api_token: tok_123\n"), "api_token", nil); err == nil || !strings.Contains(err.Error(), "not encrypted with sops") {
+		t.Fatalf("expected an error for a document without sops metadata, got %v", err)
+	}
+}
+
+func TestDecryptWithoutIdentity(t *testing.T) {
+	source, err := os.ReadFile("testdata/secrets.enc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Decrypt(source, "api_token", nil); err == nil || !strings.Contains(err.Error(), "unable to decrypt the data key") {
+		t.Fatalf("expected an error decrypting the data key, got %v", err)
+	}
+}
+
+func TestDecryptArmored(t *testing.T) {
+	t.Setenv(AgeKeyFileEnv, "testdata/keys.txt")
+
+	identities, err := Identities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.ReadFile("testdata/token.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, err := DecryptArmored(string(source), identities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "binary\x00value" {
+		t.Errorf("unexpected plaintext %q", plaintext)
+	}
+}
+
+func TestDecryptSwappedForPlaintext(t *testing.T) {
+	t.Setenv(AgeKeyFileEnv, "testdata/keys.txt")
+
+	identities, err := Identities()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := os.ReadFile("testdata/secrets.enc.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A value replaced with plaintext must not be accepted, since sops would have encrypted it.
+	lines := strings.Split(string(source), "\n")
+	tampered := strings.Replace(string(source), lines[0], "api_token: tok_456", 1)
+
+	if _, err := Decrypt([]byte(tampered), "api_token", identities); err == nil || !strings.Contains(err.Error(), "is not encrypted") {
+		t.Fatalf("expected an error for a value swapped for plaintext, got %v", err)
+	}
+}
+
+func TestDecryptUnencryptedValues(t *testing.T) {
+	// Plaintext values are accepted where the document's sops settings leave them unencrypted. The
+	// data key is only decrypted for encrypted values, so none is needed here.
+	metadata := "sops:\n    age:\n        - recipient: age1example\n          enc: unused\n"
+
+	cases := []struct {
+		name     string
+		document string
+		key      string
+		err      string
+	}{
+		{name: "unencrypted suffix", document: "host_unencrypted: example.com\n" + metadata + "    unencrypted_suffix: _unencrypted\n", key: "host_unencrypted"},
+		{name: "unencrypted suffix on a parent", document: "registry_unencrypted:\n    host: example.com\n" + metadata + "    unencrypted_suffix: _unencrypted\n", key: "registry_unencrypted.host"},
+		{name: "without unencrypted suffix", document: "host: example.com\n" + metadata + "    unencrypted_suffix: _unencrypted\n", key: "host", err: "is not encrypted"},
+		{name: "without encrypted suffix", document: "host: example.com\n" + metadata + "    encrypted_suffix: _secret\n", key: "host"},
+		{name: "encrypted suffix", document: "token_secret: tok_123\n" + metadata + "    encrypted_suffix: _secret\n", key: "token_secret", err: "is not encrypted"},
+		{name: "unencrypted regex", document: "host: example.com\n" + metadata + "    unencrypted_regex: ^host$\n", key: "host"},
+		{name: "without encrypted regex", document: "port: 5000\n" + metadata + "    encrypted_regex: ^(password|token)$\n", key: "port"},
+		{name: "encrypted regex", document: "token: tok_123\n" + metadata + "    encrypted_regex: ^(password|token)$\n", key: "token", err: "is not encrypted"},
+		{name: "no settings", document: "host: example.com\n" + metadata, key: "host", err: "is not encrypted"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Decrypt([]byte(c.document), c.key, nil)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("expected error containing %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
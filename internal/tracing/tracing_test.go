@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collector is a minimal stand-in for an OTLP/HTTP collector that records every span it receives.
+type collector struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var req collectortrace.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, resourceSpans := range req.ResourceSpans {
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			c.spans = append(c.spans, scopeSpans.Spans...)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestSetupExportsSpansToCollector(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL)
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx := context.Background()
+	shutdown, err := Setup(ctx, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, span := Start(ctx, "mint_secret.Create", VaultKey.String("default"))
+	span.End()
+
+	if err := shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(c.spans))
+	}
+
+	got := c.spans[0]
+	if got.Name != "mint_secret.Create" {
+		t.Errorf("expected span name mint_secret.Create, got %q", got.Name)
+	}
+	if traceID := hex.EncodeToString(got.TraceId); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected span to continue the TRACEPARENT trace, got trace ID %s", traceID)
+	}
+	if parentID := hex.EncodeToString(got.ParentSpanId); parentID != "00f067aa0ba902b7" {
+		t.Errorf("expected span to be parented to the TRACEPARENT span, got parent ID %s", parentID)
+	}
+	if len(got.Attributes) != 1 || got.Attributes[0].Key != "mint.vault" || got.Attributes[0].Value.GetStringValue() != "default" {
+		t.Errorf("expected mint.vault attribute, got %v", got.Attributes)
+	}
+}
+
+func TestSetupWithoutEndpointIsNoop(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	shutdown, err := Setup(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
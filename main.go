@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/rwx-research/terraform-provider-mint/internal/provider"
+	"github.com/rwx-research/terraform-provider-mint/internal/tracing"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 )
@@ -13,11 +14,25 @@ import (
 var version string = "dev"
 
 func main() {
+	ctx := context.Background()
+
 	opts := providerserver.ServeOpts{
 		Address: "registry.terraform.io/rwx-research/mint",
 	}
 
-	if err := providerserver.Serve(context.Background(), provider.New(version), opts); err != nil {
+	// Tracing is best-effort: a misconfigured exporter should never prevent the provider from serving.
+	shutdownTracing, err := tracing.Setup(ctx, version)
+	if err != nil {
+		log.Printf("[WARN] OpenTelemetry tracing disabled: %s", err.Error())
+	}
+
+	err = providerserver.Serve(ctx, provider.New(version), opts)
+
+	if shutdownErr := shutdownTracing(ctx); shutdownErr != nil {
+		log.Printf("[WARN] Unable to flush OpenTelemetry spans: %s", shutdownErr.Error())
+	}
+
+	if err != nil {
 		log.Fatal(err.Error())
 	}
 }